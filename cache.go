@@ -0,0 +1,305 @@
+package form
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// formKind classifies how values of a Go type are laid out in form keys.
+type formKind int
+
+const (
+	// kindScalar values hold a single form value, such as strings, numbers, and TextUnmarshaler types.
+	kindScalar formKind = iota
+
+	// kindStruct values are nested structs, with fields scoped under the parent's form key.
+	kindStruct
+
+	// kindMap values hold one entry per bracketed key: `field[key]`.
+	kindMap
+
+	// kindSlice values hold repeated form values for a single key.
+	kindSlice
+
+	// kindIndexedSlice values hold structured elements under indexed keys: `field[0][name]`.
+	kindIndexedSlice
+
+	// kindFile values hold uploaded files from a multipart form: *multipart.FileHeader and slices of them.
+	kindFile
+)
+
+var (
+	// structCache holds the *structInfo for each struct type and field naming configuration. It is shared by every
+	// Decoder and Encoder without registered conversions.
+	structCache sync.Map
+
+	// kindCache holds the formKind for each type.
+	kindCache sync.Map
+)
+
+// structKey identifies a struct type analyzed with a field naming configuration, with or without validation rules.
+type structKey struct {
+	typ      reflect.Type
+	tagNames string
+	naming   NamingStrategy
+	validate bool
+}
+
+// registry holds the custom conversions registered with a Decoder or Encoder. Registered types are scalar values, so
+// a registry has its own kind, struct, and validation caches, which are released along with the registry. A registry
+// is never modified once it is in use: registering a type creates a new registry with empty caches.
+type registry struct {
+	converters map[reflect.Type]func(string) (reflect.Value, error)
+	marshalers map[reflect.Type]func(reflect.Value) (string, error)
+
+	// kinds holds the formKind for each type, with registered types classified as scalar values.
+	kinds sync.Map
+
+	// structs and validations hold the registry's entries of the struct cache and validation cache.
+	structs     sync.Map
+	validations sync.Map
+}
+
+// withConverter returns a copy of the registry with the converter registered for the type.
+func (r *registry) withConverter(t reflect.Type, convert func(string) (reflect.Value, error)) *registry {
+	next := r.clone()
+	next.converters[t] = convert
+
+	return next
+}
+
+// withMarshaler returns a copy of the registry with the marshaler registered for the type.
+func (r *registry) withMarshaler(t reflect.Type, marshal func(reflect.Value) (string, error)) *registry {
+	next := r.clone()
+	next.marshalers[t] = marshal
+
+	return next
+}
+
+// clone returns a copy of the registry's conversions, with empty caches.
+func (r *registry) clone() *registry {
+	next := &registry{
+		converters: map[reflect.Type]func(string) (reflect.Value, error){},
+		marshalers: map[reflect.Type]func(reflect.Value) (string, error){},
+	}
+	if r != nil {
+		maps.Copy(next.converters, r.converters)
+		maps.Copy(next.marshalers, r.marshalers)
+	}
+
+	return next
+}
+
+// isRegistered reports whether the type, or the type a pointer references, has a registered conversion.
+func (r *registry) isRegistered(t reflect.Type) bool {
+	if r == nil {
+		return false
+	}
+
+	for {
+		if _, ok := r.converters[t]; ok {
+			return true
+		}
+		if _, ok := r.marshalers[t]; ok {
+			return true
+		}
+		if t.Kind() != reflect.Pointer {
+			return false
+		}
+
+		t = t.Elem()
+	}
+}
+
+// structInfo holds the precomputed metadata for a struct type.
+type structInfo struct {
+	fields []fieldInfo
+
+	// byTag holds the position in fields of each form tag, for looking up the field of a form key.
+	byTag map[string]int
+
+	// checkAbsent reports whether any field has default values to apply, or is required, when the struct's form keys
+	// are absent.
+	checkAbsent bool
+}
+
+// decodeFunc decodes the form values under formTag into dest.
+type decodeFunc func(d *decodeState, dest reflect.Value, formTag string) error
+
+// fieldInfo holds the precomputed metadata for an exported struct field with a form tag.
+type fieldInfo struct {
+	tagOptions
+
+	index int
+	name  string
+	tag   string
+	kind  formKind
+
+	// rules holds the validation rules from the field's `validate` tag.
+	rules []rule
+
+	// err is the error from the field's invalid tags, if any.
+	err error
+
+	// checkAbsent reports whether the field, or a struct value nested within it, has default values or is required.
+	checkAbsent bool
+
+	// decode and encode handle the field's value. Scalar fields skip the type inspection done for structured
+	// fields.
+	decode decodeFunc
+	encode func(e *Encoder, src reflect.Value, formTag string, shouldOmitEmpty bool) error
+}
+
+// cachedStructInfo returns the metadata for the struct type with the configured field naming, analyzing the type on
+// first use.
+func cachedStructInfo(t reflect.Type, o *options) *structInfo {
+	cache := &structCache
+	if o.registry != nil {
+		cache = &o.registry.structs
+	}
+
+	key := structKey{typ: t, tagNames: o.tagKey, naming: o.naming, validate: o.validate}
+	if info, ok := cache.Load(key); ok {
+		return info.(*structInfo)
+	}
+
+	info, _ := cache.LoadOrStore(key, newStructInfo(t, o))
+	return info.(*structInfo)
+}
+
+// newStructInfo analyzes the fields of the struct type. Unexported fields, fields without a form key, and fields
+// tagged with `form:"-"` are skipped.
+func newStructInfo(t reflect.Type, o *options) *structInfo {
+	info := &structInfo{byTag: map[string]int{}}
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		formTag, tagOpts, err := parseFieldTag(fieldType, o)
+		if formTag == "" || formTag == "-" {
+			continue
+		}
+		if _, ok := info.byTag[formTag]; !ok {
+			info.byTag[formTag] = len(info.fields)
+		}
+
+		field := fieldInfo{
+			tagOptions: tagOpts,
+			index:      i,
+			name:       fieldType.Name,
+			tag:        formTag,
+			kind:       o.registry.kindOf(fieldType.Type),
+			decode:     (*decodeState).decodeFormField,
+			encode:     (*Encoder).encodeFormField,
+		}
+		if field.kind == kindScalar {
+			field.decode = (*decodeState).decodeScalarField
+			field.encode = (*Encoder).encodeScalarField
+
+			// io.Reader fields are written as file parts by MultipartEncoder
+			if fieldType.Type == readerType {
+				field.encode = (*Encoder).encodeFormField
+			}
+		}
+
+		// Fields with invalid tags fail every time they are decoded or encoded, like fields of unsupported types
+		if err != nil {
+			field.err = err
+			field.decode = func(*decodeState, reflect.Value, string) error { return err }
+			field.encode = func(*Encoder, reflect.Value, string, bool) error { return err }
+			info.fields = append(info.fields, field)
+			continue
+		}
+
+		// Validation rules and default values are only used when decoding, so invalid ones do not affect encoding.
+		// Rules are only parsed with WithValidation, so that tags meant for other validators are left alone.
+		if validateTag, ok := fieldType.Tag.Lookup("validate"); ok && o.validate {
+			field.rules, err = parseRules(fieldType.Type, validateTag, o)
+		}
+
+		if err == nil && tagOpts.defaults != nil {
+			err = checkDefault(fieldType.Type, tagOpts, o)
+			field.decode = decodeWithDefault(field.decode, field.kind, tagOpts.defaults)
+		}
+
+		if err != nil {
+			field.err = err
+			field.decode = func(*decodeState, reflect.Value, string) error { return err }
+		}
+
+		// Struct values always exist, so their defaults and required fields apply even when none of their keys are in
+		// the form. Pointers to structs are optional.
+		field.checkAbsent = err == nil && (tagOpts.defaults != nil || tagOpts.required)
+		if err == nil && !tagOpts.required && field.kind == kindStruct && fieldType.Type.Kind() == reflect.Struct {
+			field.checkAbsent = cachedStructInfo(fieldType.Type, o).checkAbsent
+		}
+
+		info.checkAbsent = info.checkAbsent || field.checkAbsent
+		info.fields = append(info.fields, field)
+	}
+
+	return info
+}
+
+// kindOf returns the form layout of the type, classifying the type on first use. A nil registry uses the shared kind
+// cache.
+func (r *registry) kindOf(t reflect.Type) formKind {
+	cache := &kindCache
+	if r != nil {
+		cache = &r.kinds
+	}
+
+	if kind, ok := cache.Load(t); ok {
+		return kind.(formKind)
+	}
+
+	kind := r.classifyKind(t)
+	cache.Store(t, kind)
+
+	return kind
+}
+
+// classifyKind returns the form layout of the type. Pointers are classified by the type they reference, and registered
+// types and text (un)marshalers are always scalar values.
+func (r *registry) classifyKind(t reflect.Type) formKind {
+	if r.isRegistered(t) {
+		return kindScalar
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == fileHeaderType {
+		return kindFile
+	}
+
+	ptr := reflect.PointerTo(t)
+	if ptr.Implements(textUnmarshalerType) || ptr.Implements(textMarshalerType) {
+		return kindScalar
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return kindStruct
+
+	case reflect.Map:
+		return kindMap
+
+	case reflect.Slice:
+		switch r.kindOf(t.Elem()) {
+		case kindScalar:
+			return kindSlice
+		case kindFile:
+			return kindFile
+		default:
+			return kindIndexedSlice
+		}
+
+	default:
+		return kindScalar
+	}
+}
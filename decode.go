@@ -2,10 +2,13 @@ package form
 
 import (
 	"encoding"
+	"errors"
 	"fmt"
+	"mime/multipart"
 	"reflect"
-	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,17 +17,6 @@ var (
 	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
 )
 
-// ErrorDecode represents an error that occurs during the decoding process.
-type ErrorDecode struct {
-	fieldName string
-	err       error
-}
-
-// Error returns the error message for ErrorDecode.
-func (e ErrorDecode) Error() string {
-	return fmt.Sprintf("Unable to decode tag '%s': %s", e.fieldName, e.err)
-}
-
 // Unmarshal iterates over the fields in `dest`, populating them with the appropriate fields from the provided source
 // map. `src` is a map containing form values, and `dest` is a pointer to the struct that will be populated.
 //
@@ -43,65 +35,171 @@ func (e ErrorDecode) Error() string {
 // Dynamic values are encoded in the keys with a `field[key] = val` syntax. Use `map[string]<type>` as the struct type
 // to unmarshal these dynamic pairs.
 //
+// Nested structs are scoped under their parent's tag: `parent[field] = val`, or `parent.field = val` when decoding
+// with WithPathSyntax(PathDot).
+//
 // If multiple form values are provided for a field, parse all values. If the value is not a slice, the first form value
-// is set to the struct's field.
-func Unmarshal(src map[string][]string, dest any) error {
-	return NewDecoder(src).Decode(dest)
+// is set to the struct's field, unless another policy is chosen with WithDuplicates or the `dup` tag option.
+func Unmarshal(src map[string][]string, dest any, opts ...Option) error {
+	return NewDecoder(opts...).Decode(src, dest)
 }
 
-// Decoder is responsible for decoding form data from the source map to the provided destination struct.
+// Decoder is responsible for decoding form data from a source map to a destination struct. A Decoder holds only its
+// configuration, so a single Decoder can be created at startup and used by many goroutines at once.
+//
+// Example:
+//
+//	var decoder = form.NewDecoder(form.WithAllErrors())
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		...
+//		var submission SampleForm
+//		err := decoder.Decode(r.Form, &submission)
+//		if err != nil { ... }
+//	}
 type Decoder struct {
+	opts options
+}
+
+// NewDecoder creates a new Decoder instance with the given options.
+func NewDecoder(opts ...Option) *Decoder {
+	return &Decoder{opts: newOptions(opts)}
+}
+
+// RegisterConverter registers a function that decodes form values into values of the type, for types that cannot
+// implement encoding.TextUnmarshaler, such as types from other packages. The converter takes precedence over every
+// other conversion, and is used for struct fields, slice elements, map keys, and map values of the type or of pointers
+// to it. Registered types are decoded as single values, even if they are structs.
+//
+// Converters must be registered before the Decoder is used. RegisterConverter is not safe for concurrent use with
+// Decode.
+//
+// Example:
+//
+//	decoder := form.NewDecoder()
+//	decoder.RegisterConverter(reflect.TypeOf(decimal.Decimal{}), func(value string) (reflect.Value, error) {
+//		d, err := decimal.NewFromString(value)
+//		return reflect.ValueOf(d), err
+//	})
+func (d *Decoder) RegisterConverter(t reflect.Type, convert func(string) (reflect.Value, error)) {
+	d.opts.registry = d.opts.registry.withConverter(t, convert)
+}
+
+// decodeState holds the state of a single call to Decoder.Decode.
+type decodeState struct {
+	*Decoder
+
 	src map[string][]string
+
+	// files holds the uploaded files, when decoding a multipart form.
+	files map[string][]*multipart.FileHeader
+
+	// errs collects decode errors when the Decoder is created with WithAllErrors.
+	errs DecodeErrors
+
+	// index holds the nested structure of the source keys. It is built the first time a nested key is looked up.
+	index *keyIndex
+
+	// consumed holds the source keys that were read while decoding, when the Decoder is created with WithStrict.
+	consumed map[string]bool
+
+	// duplicates is the DuplicatePolicy of the field being decoded.
+	duplicates DuplicatePolicy
+
+	// layout is the time layout of the field being decoded.
+	layout string
+
+	// uploads holds the restrictions on uploaded files of the field being decoded.
+	uploads uploadLimits
 }
 
-// NewDecoder creates a new Decoder instance with the given source form data.
-func NewDecoder(src map[string][]string) *Decoder {
-	return &Decoder{src: src}
+// Decode decodes the form data in `src` into the provided destination struct by iterating over the fields in `dest`.
+// The `dest` must be a pointer to a struct. Decode is safe for concurrent use.
+func (d *Decoder) Decode(src map[string][]string, dest any) error {
+	return d.decode(src, nil, dest)
 }
 
-// Decode decodes the form data into the provided destination struct by iterating over the fields in `dest`.
-// The `dest` must be a pointer to a struct.
-func (d *Decoder) Decode(dest any) error {
+// decode decodes the form values and uploaded files into the destination struct.
+func (d *Decoder) decode(src map[string][]string, files map[string][]*multipart.FileHeader, dest any) error {
 	// Ensure dest has a value that is a non-nil pointer to a struct
 	val := reflect.ValueOf(dest)
 	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Struct {
-		return fmt.Errorf("destination (%v) must be a pointer to a struct", reflect.TypeOf(dest))
+		return fmt.Errorf("%w: destination (%v) must be a pointer to a struct", ErrInvalidDestination, reflect.TypeOf(dest))
 	}
 
 	// Get value of dest pointer
 	val = val.Elem()
 
-	err := d.decodeStruct(val)
+	state := &decodeState{Decoder: d, src: src, files: files, duplicates: d.opts.duplicates}
+	if d.opts.strict {
+		state.consumed = map[string]bool{}
+	}
+
+	err := state.checkLimits()
 	if err != nil {
 		return err
 	}
 
-	return nil
-}
+	err = state.decodeStruct(val, "")
+	if err != nil {
+		return err
+	}
 
-// decodeStruct iterates over the fields of the provided struct and decodes them from form values.
-func (d *Decoder) decodeStruct(dest reflect.Value) error {
-	// Iterate over the fields in dest
-	destType := dest.Type()
-	for i := 0; i < dest.NumField(); i++ {
-		fieldType := destType.Field(i)
-		if !fieldType.IsExported() {
-			continue
+	if d.opts.strict {
+		err = state.checkUnknownKeys()
+		if err != nil {
+			return err
 		}
+	}
 
-		// Ignore omitempty flag when decoding
-		formTag, _ := parseFieldTag(fieldType)
+	// Values are only validated once every field has decoded
+	if len(state.errs) == 0 {
+		state.validateValue(val, "")
+	}
+
+	if len(state.errs) > 0 {
+		return state.errs
+	}
 
-		if formTag != "" && formTag != "-" {
-			// Parse based on field type. All field types but map look up their values from src. Map must iterate over
-			// src keys to find all relevant key/value pairs. Map key/value parsing is done once and cached for
-			// additional map fields.
-			fieldVal := dest.Field(i)
+	return nil
+}
 
-			err := d.decodeFormField(fieldVal, formTag)
+// decodeStruct iterates over the fields of the provided struct and decodes them from form values. Field keys are
+// scoped under `prefix`, which is empty for the top-level struct.
+func (d *decodeState) decodeStruct(dest reflect.Value, prefix string) error {
+	// Iterate over the cached fields in dest
+	destType := dest.Type()
+	for _, field := range cachedStructInfo(destType, &d.opts).fields {
+		fieldVal := dest.Field(field.index)
+		fieldTag := d.opts.pathSyntax.joinField(prefix, field.tag)
+
+		// Required fields are reported as missing without decoding them. Empty values still count as known keys.
+		if field.required && d.isMissing(field.kind, fieldTag) {
+			d.consume(fieldTag)
+			err := d.collect(ErrorDecode{Path: fieldTag, Field: field.name, Struct: destType, Err: ErrMissing}, fieldTag, "")
 			if err != nil {
 				return err
 			}
+
+			continue
+		}
+
+		// Fields with a `dup` tag option override the policy for every value nested within them
+		duplicates, layout, uploads := d.duplicates, d.layout, d.uploads
+		if field.duplicates != 0 {
+			d.duplicates = field.duplicates
+		}
+		d.layout, d.uploads = field.layout, field.uploads
+
+		// Errors are attributed to the innermost struct field that produced them
+		errCount := len(d.errs)
+		err := d.collect(field.decode(d, fieldVal, fieldTag), fieldTag, "")
+		d.duplicates, d.layout, d.uploads = duplicates, layout, uploads
+		for j := errCount; j < len(d.errs); j++ {
+			d.errs[j] = d.errs[j].withField(destType, field.name)
+		}
+		if err != nil {
+			return err.(ErrorDecode).withField(destType, field.name)
 		}
 	}
 
@@ -109,67 +207,206 @@ func (d *Decoder) decodeStruct(dest reflect.Value) error {
 }
 
 // decodeFormField decodes the form value into the provided struct field based on the form tag.
-func (d *Decoder) decodeFormField(dest reflect.Value, formTag string) error {
-	if dest.Kind() != reflect.Map && len(d.src[formTag]) == 0 {
-		return nil
+func (d *decodeState) decodeFormField(dest reflect.Value, formTag string) error {
+	kind := d.opts.registry.kindOf(dest.Type())
+	if kind == kindScalar {
+		return d.decodeScalarField(dest, formTag)
 	}
 
-	// Check overridden TextUnmarshaler types first.
-	if dest.Type().Implements(textUnmarshalerType) ||
-		(dest.CanAddr() && dest.Addr().Type().Implements(textUnmarshalerType)) {
-		if !dest.Type().Implements(textUnmarshalerType) {
-			return d.decodeFormField(dest.Addr(), formTag)
+	if !d.isPresent(kind, formTag) {
+		if kind == kindStruct && dest.Kind() == reflect.Struct {
+			return d.decodeAbsentStruct(dest, formTag)
 		}
 
+		return nil
+	}
+
+	if kind == kindFile {
+		return d.decodeFileField(dest, formTag)
+	}
+
+	// Decode the element the pointer references.
+	for dest.Kind() == reflect.Pointer {
 		ensurePointerIsSet(dest)
-		f := dest.MethodByName("UnmarshalText")
-		rawValue := []byte(d.src[formTag][0])
-		unmarshalArg := []reflect.Value{reflect.ValueOf(rawValue)}
-		ret := f.Call(unmarshalArg)
-		if !ret[0].IsNil() {
-			return ErrorDecode{fieldName: formTag, err: ret[0].Interface().(error)}
+		dest = dest.Elem()
+	}
+
+	// Parse based on field type. All field types but map, indexed slice, and struct look up their values directly from
+	// src. The others are decoded from the src keys scoped under the form tag.
+	switch kind {
+	case kindSlice:
+		d.consume(formTag)
+		err := checkLen("slice length", d.opts.maxSliceLen, len(d.src[formTag]), formTag)
+		if err != nil {
+			return err
 		}
 
+		return d.decodeSliceValue(dest, d.src[formTag], formTag)
+
+	case kindIndexedSlice:
+		return d.decodeIndexedSlice(dest, formTag)
+
+	case kindMap:
+		return d.decodeMap(dest, formTag)
+
+	default:
+		return d.decodeStruct(dest, formTag)
+	}
+}
+
+// decodeAbsentStruct applies the default values and reports the required fields of a struct value whose form keys are
+// all absent, including those of the struct values nested within it. Pointers to structs are left nil, so the fields
+// of optional structs are not required.
+func (d *decodeState) decodeAbsentStruct(dest reflect.Value, prefix string) error {
+	destType := dest.Type()
+	info := cachedStructInfo(destType, &d.opts)
+	if !info.checkAbsent {
 		return nil
 	}
 
-	if dest.Kind() == reflect.Pointer {
-		// Decode the element the pointer references.
+	for _, field := range info.fields {
+		if !field.checkAbsent {
+			continue
+		}
+
+		fieldTag := d.opts.pathSyntax.joinField(prefix, field.tag)
+		if field.required {
+			err := d.collect(ErrorDecode{Path: fieldTag, Field: field.name, Struct: destType, Err: ErrMissing}, fieldTag, "")
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		layout := d.layout
+		d.layout = field.layout
+		err := field.decode(d, dest.Field(field.index), fieldTag)
+		d.layout = layout
+		err = d.collect(err, fieldTag, "")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeWithDefault returns a decode func that decodes the default values when the field's form key is absent.
+func decodeWithDefault(decode decodeFunc, kind formKind, defaults []string) decodeFunc {
+	return func(d *decodeState, dest reflect.Value, formTag string) error {
+		if d.isPresent(kind, formTag) {
+			return decode(d, dest, formTag)
+		}
+
+		return d.decodeDefault(dest, defaults, formTag)
+	}
+}
+
+// decodeDefault decodes the default values from a field's tag through the same conversions as form values.
+func (d *decodeState) decodeDefault(dest reflect.Value, defaults []string, formTag string) error {
+	if d.opts.registry.kindOf(dest.Type()) == kindScalar {
+		return d.decodeValue(dest, defaults[0], formTag)
+	}
+
+	for dest.Kind() == reflect.Pointer {
 		ensurePointerIsSet(dest)
-		return d.decodeFormField(dest.Elem(), formTag)
+		dest = dest.Elem()
 	}
 
-	// Check for structured types
-	switch dest.Kind() {
-	case reflect.Slice:
-		return d.decodeSliceField(dest, formTag)
+	return d.decodeSliceValue(dest, defaults, formTag)
+}
 
-	case reflect.Map:
-		return d.decodeMap(dest, formTag)
+// newProbeState returns a decodeState for decoding values from struct tags while a type is analyzed. The state stops at
+// the first error.
+func newProbeState(o *options) *decodeState {
+	probe := &decodeState{Decoder: &Decoder{opts: *o}}
+	probe.opts.allErrors = false
 
-	case reflect.Struct:
-		return d.decodeStruct(dest)
+	return probe
+}
 
-	default:
-		break
+// checkDefault returns an error if the default values from a field's tag cannot be decoded into the field's type.
+// Defaults are checked once, when the struct type is analyzed, so an invalid default fails every decode rather than
+// only requests that omit the field.
+func checkDefault(t reflect.Type, opts tagOptions, o *options) error {
+	kind := o.registry.kindOf(t)
+	if kind != kindScalar && kind != kindSlice {
+		return fmt.Errorf("%w: default values are not supported for %v", ErrInvalidTag, t)
 	}
 
-	// Decode value. Take the first value from the source slice.
-	var strVal string
-	if len(d.src[formTag]) > 0 {
-		strVal = d.src[formTag][0]
+	// Decode into a throwaway value, with the field's time layout
+	probe := newProbeState(o)
+	probe.layout = opts.layout
+	err := probe.decodeDefault(reflect.New(t).Elem(), opts.defaults, "")
+	if err != nil {
+		return fmt.Errorf("%w: invalid default value: %w", ErrInvalidTag, errors.Unwrap(err))
 	}
 
-	return d.decodeValue(dest, strVal, formTag)
+	return nil
+}
+
+// decodeScalarField decodes a single form value into the provided field. If multiple form values are provided, the
+// value is chosen by the field's DuplicatePolicy.
+func (d *decodeState) decodeScalarField(dest reflect.Value, formTag string) error {
+	rawValues := d.src[formTag]
+	if len(rawValues) == 0 {
+		return nil
+	}
+
+	d.consume(formTag)
+	rawValue, err := d.duplicates.resolve(rawValues)
+	if err != nil {
+		return ErrorDecode{Path: formTag, Err: err}
+	}
+
+	return d.decodeValue(dest, rawValue, formTag)
 }
 
 // decodeValue decodes a single value from the form into the provided destination value.
-func (d *Decoder) decodeValue(dest reflect.Value, rawValue, formTag string) error {
+func (d *decodeState) decodeValue(dest reflect.Value, rawValue, formTag string) error {
+	// Registered converters take precedence over every other conversion
+	if d.opts.registry.isRegistered(dest.Type()) {
+		return d.convertValue(dest, rawValue, formTag)
+	}
+
+	// Time layouts from the field's tag take precedence over time.Time's text format
+	if d.layout != "" && (dest.Type() == timeType || dest.Type() == timePtrType) {
+		if dest.Kind() == reflect.Pointer {
+			ensurePointerIsSet(dest)
+			dest = dest.Elem()
+		}
+
+		t, err := parseTime(d.layout, rawValue, d.opts.location())
+		if err != nil {
+			return ErrorDecode{Path: formTag, Value: rawValue, Err: err}
+		}
+
+		dest.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	// Check overridden TextUnmarshaler types first. If only the pointer implements TextUnmarshaler, decode through the
+	// pointer.
+	if isTextUnmarshaler(dest) {
+		if !dest.Type().Implements(textUnmarshalerType) {
+			return d.decodeValue(dest.Addr(), rawValue, formTag)
+		}
+
+		ensurePointerIsSet(dest)
+		err := dest.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(rawValue))
+		if err != nil {
+			return ErrorDecode{Path: formTag, Value: rawValue, Err: err}
+		}
+
+		return nil
+	}
+
 	switch dest.Type() {
 	case durationType:
 		duration, err := time.ParseDuration(rawValue)
 		if err != nil {
-			return ErrorDecode{fieldName: formTag, err: err}
+			return ErrorDecode{Path: formTag, Value: rawValue, Err: err}
 		}
 		dest.Set(reflect.ValueOf(duration))
 		return nil
@@ -179,28 +416,28 @@ func (d *Decoder) decodeValue(dest reflect.Value, rawValue, formTag string) erro
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		i, err := strconv.ParseInt(rawValue, 0, dest.Type().Bits())
 		if err != nil {
-			return ErrorDecode{fieldName: formTag, err: err}
+			return ErrorDecode{Path: formTag, Value: rawValue, Err: err}
 		}
 		dest.SetInt(i)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		i, err := strconv.ParseUint(rawValue, 0, dest.Type().Bits())
 		if err != nil {
-			return ErrorDecode{fieldName: formTag, err: err}
+			return ErrorDecode{Path: formTag, Value: rawValue, Err: err}
 		}
 		dest.SetUint(i)
 
 	case reflect.Float32, reflect.Float64:
 		f, err := strconv.ParseFloat(rawValue, dest.Type().Bits())
 		if err != nil {
-			return ErrorDecode{fieldName: formTag, err: err}
+			return ErrorDecode{Path: formTag, Value: rawValue, Err: err}
 		}
 		dest.SetFloat(f)
 
 	case reflect.Bool:
 		b, err := strconv.ParseBool(rawValue)
 		if err != nil {
-			return ErrorDecode{fieldName: formTag, err: err}
+			return ErrorDecode{Path: formTag, Value: rawValue, Err: err}
 		}
 		dest.SetBool(b)
 
@@ -213,26 +450,93 @@ func (d *Decoder) decodeValue(dest reflect.Value, rawValue, formTag string) erro
 		return d.decodeValue(dest.Elem(), rawValue, formTag)
 
 	default:
-		return fmt.Errorf("unsupported type %v", dest.Type())
+		return ErrorDecode{Path: formTag, Value: rawValue, Err: fmt.Errorf("%w %v", ErrUnsupportedType, dest.Type())}
 	}
 
 	return nil
 }
 
-// decodeSliceField decodes the form values into the provided slice field.
-func (d *Decoder) decodeSliceField(dest reflect.Value, formTag string) error {
-	return d.decodeSliceValue(dest, d.src[formTag], formTag)
+// convertValue decodes a form value with the converter registered for the destination's type. Pointers are allocated
+// until they reference a registered type.
+func (d *decodeState) convertValue(dest reflect.Value, rawValue, formTag string) error {
+	convert, ok := d.opts.registry.converters[dest.Type()]
+	if !ok {
+		if dest.Kind() != reflect.Pointer {
+			return ErrorDecode{Path: formTag, Value: rawValue, Err: fmt.Errorf("%w %v", ErrUnsupportedType, dest.Type())}
+		}
+
+		ensurePointerIsSet(dest)
+		return d.convertValue(dest.Elem(), rawValue, formTag)
+	}
+
+	val, err := convert(rawValue)
+	if err != nil {
+		return ErrorDecode{Path: formTag, Value: rawValue, Err: err}
+	}
+	if !val.IsValid() || !val.Type().AssignableTo(dest.Type()) {
+		return ErrorDecode{Path: formTag, Value: rawValue, Err: fmt.Errorf("converter for %v returned %v", dest.Type(), val)}
+	}
+
+	dest.Set(val)
+
+	return nil
+}
+
+// decodeIndexedSlice decodes indexed form keys (`items[0][name]`, `items[1][name]`) into the provided slice field.
+// Elements are allocated in index order. Gaps between indexes are dropped, so `items[0]` and `items[5]` decode into a
+// slice of length two.
+func (d *decodeState) decodeIndexedSlice(dest reflect.Value, formTag string) error {
+	segments := d.keys().segments(formTag)
+	err := checkLen("slice length", d.opts.maxSliceLen, len(segments), formTag)
+	if err != nil {
+		return err
+	}
+
+	indexes := make([]int, 0, len(segments))
+	for _, segment := range segments {
+		i, err := parseIndex(segment)
+		if err != nil {
+			// The keys under an invalid index are reported once, as the invalid index, rather than as unknown keys
+			d.consumeScoped(joinIndex(formTag, segment))
+			err = d.collect(err, formTag, segment)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	slice := reflect.MakeSlice(dest.Type(), len(indexes), len(indexes))
+	for i, index := range indexes {
+		elemTag := joinIndex(formTag, strconv.Itoa(index))
+		err := d.collect(d.decodeFormField(slice.Index(i), elemTag), elemTag, strconv.Itoa(index))
+		if err != nil {
+			return err
+		}
+	}
+	dest.Set(slice)
+
+	return nil
 }
 
 // decodeSliceValue decodes the values from the source slice into the provided destination slice.
-func (d *Decoder) decodeSliceValue(dest reflect.Value, rawValues []string, formTag string) error {
+func (d *decodeState) decodeSliceValue(dest reflect.Value, rawValues []string, formTag string) error {
 	sliceType := dest.Type()
 
-	for _, val := range rawValues {
+	for i, val := range rawValues {
 		elem := reflect.New(sliceType.Elem()).Elem()
 		err := d.decodeValue(elem, val, formTag)
 		if err != nil {
-			return err
+			err = d.collect(err, formTag, strconv.Itoa(i))
+			if err != nil {
+				return err
+			}
+
+			continue
 		}
 
 		dest.Set(reflect.Append(dest, elem))
@@ -241,45 +545,52 @@ func (d *Decoder) decodeSliceValue(dest reflect.Value, rawValues []string, formT
 	return nil
 }
 
-// decodeMap decodes the form values into the provided map field.
-func (d *Decoder) decodeMap(dest reflect.Value, formTag string) error {
-	regex, err := regexp.Compile(fmt.Sprintf("^%s\\[(.*)]$", formTag))
+// decodeMap decodes the form values into the provided map field. Each bracketed segment directly under the form tag
+// is a map key, and the map value is decoded from the keys scoped under `tag[key]`. Map values may be any supported
+// type, including structs, slices of structs, and other maps: `meta[a][b] = val`. Map keys are decoded like scalar
+// values, so integer, unsigned, bool, and TextUnmarshaler keys are supported.
+func (d *decodeState) decodeMap(dest reflect.Value, formTag string) error {
+	segments := d.keys().segments(formTag)
+	err := checkLen("map size", d.opts.maxMapSize, len(segments), formTag)
 	if err != nil {
-		return ErrorDecode{fieldName: formTag, err: err}
+		return err
 	}
 
 	mapType := dest.Type()
 	m := reflect.MakeMap(mapType)
+	elemKind := d.opts.registry.kindOf(mapType.Elem())
 
-	// Find all src keys that match the form tag.
-	for rawKey, val := range d.src {
-		captureGroups := regex.FindStringSubmatch(rawKey)
-		if len(captureGroups) == 0 || len(val) == 0 {
+	for _, segment := range segments {
+		entryTag := joinIndex(formTag, segment)
+		if !d.isPresent(elemKind, entryTag) {
 			continue
 		}
 
-		if len(captureGroups) != 2 {
-			return ErrorDecode{fieldName: formTag, err: fmt.Errorf("invalid map key: %v", captureGroups)}
-		}
-
-		// Handle single values or slices.
-		sliceType := mapType.Elem()
-		sliceVal := reflect.New(sliceType).Elem()
-		if mapType.Elem().Kind() == reflect.Slice {
-			err = d.decodeSliceValue(sliceVal, val, formTag)
+		mapKey := reflect.New(mapType.Key()).Elem()
+		err := d.decodeValue(mapKey, segment, entryTag)
+		if err != nil {
+			// The keys under an invalid map key are reported once, as the invalid map key, rather than as unknown keys
+			d.consumeScoped(entryTag)
+			err = d.collect(err, entryTag, segment)
 			if err != nil {
-				return ErrorDecode{fieldName: formTag, err: fmt.Errorf("error decoding map slice: %v", err)}
+				return err
 			}
 
-			m.SetMapIndex(reflect.ValueOf(captureGroups[1]), sliceVal)
-		} else {
-			err = d.decodeValue(sliceVal, val[0], formTag)
-			if err != nil {
-				return ErrorDecode{fieldName: formTag, err: fmt.Errorf("error decoding map value: %v", err)}
-			}
+			continue
+		}
 
-			m.SetMapIndex(reflect.ValueOf(captureGroups[1]), sliceVal)
+		// Leave out map values that failed to decode
+		errCount := len(d.errs)
+		mapVal := reflect.New(mapType.Elem()).Elem()
+		err = d.collect(d.decodeFormField(mapVal, entryTag), entryTag, segment)
+		if err != nil {
+			return err
 		}
+		if len(d.errs) > errCount {
+			continue
+		}
+
+		m.SetMapIndex(mapKey, mapVal)
 	}
 
 	if m.Len() > 0 {
@@ -289,6 +600,149 @@ func (d *Decoder) decodeMap(dest reflect.Value, formTag string) error {
 	return nil
 }
 
+// collect handles an error that occurred while decoding the given form key, at the given slice index or map key, if
+// any. When the Decoder aggregates errors, the error is recorded and nil is returned so decoding continues. Otherwise,
+// the error is returned as an ErrorDecode.
+func (d *decodeState) collect(err error, formTag, index string) error {
+	if err == nil {
+		return nil
+	}
+
+	decodeErr, ok := err.(ErrorDecode)
+	if !ok {
+		decodeErr = ErrorDecode{Path: formTag, Err: err}
+	}
+	if decodeErr.Index == "" {
+		decodeErr.Index = index
+	}
+
+	if !d.opts.allErrors {
+		return decodeErr
+	}
+
+	d.errs = append(d.errs, decodeErr)
+
+	return nil
+}
+
+// isPresent reports whether the source holds any values for a field of the given kind with the given form key. Maps,
+// indexed slices, and nested structs are present if any source key is scoped under the form key.
+func (d *decodeState) isPresent(kind formKind, formTag string) bool {
+	switch kind {
+	case kindMap, kindIndexedSlice:
+		return len(d.keys().segments(formTag)) > 0
+
+	case kindStruct:
+		return d.keys().hasFields(formTag)
+
+	case kindFile:
+		return len(d.files[formTag]) > 0
+
+	default:
+		return len(d.src[formTag]) > 0
+	}
+}
+
+// consume marks the source key as read, for strict decoding.
+func (d *decodeState) consume(formTag string) {
+	if d.consumed != nil {
+		d.consumed[formTag] = true
+	}
+}
+
+// consumeScoped marks the source key, and every source key scoped under it, as read, for strict decoding.
+func (d *decodeState) consumeScoped(formTag string) {
+	if d.consumed == nil {
+		return
+	}
+
+	scoped := func(key string) bool {
+		rest, ok := strings.CutPrefix(key, formTag)
+		return ok && (rest == "" || rest[0] == '[' || (rest[0] == '.' && d.opts.pathSyntax == PathDot))
+	}
+	for key := range d.src {
+		if scoped(key) {
+			d.consumed[key] = true
+		}
+	}
+	for key := range d.files {
+		if scoped(key) {
+			d.consumed[key] = true
+		}
+	}
+}
+
+// checkUnknownKeys returns an error for each source key with values that was not read while decoding, and is not in
+// the allowlist. When the Decoder aggregates errors, the errors are recorded and nil is returned.
+func (d *decodeState) checkUnknownKeys() error {
+	unknown := map[string]string{}
+	for key, val := range d.src {
+		if len(val) > 0 && !d.consumed[key] && !d.opts.allowedKeys[key] {
+			unknown[key] = val[0]
+		}
+	}
+	for key, headers := range d.files {
+		if len(headers) > 0 && !d.consumed[key] && !d.opts.allowedKeys[key] {
+			unknown[key] = headers[0].Filename
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(unknown))
+	for key := range unknown {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		d.errs = append(d.errs, ErrorDecode{Path: key, Value: unknown[key], Err: ErrUnknownKey})
+	}
+
+	if d.opts.allErrors {
+		return nil
+	}
+
+	return d.errs
+}
+
+// isMissing reports whether a required field has no form values. Like the HTML `required` attribute, form keys that
+// only have empty values are missing.
+func (d *decodeState) isMissing(kind formKind, formTag string) bool {
+	if kind != kindScalar && kind != kindSlice {
+		return !d.isPresent(kind, formTag)
+	}
+
+	for _, val := range d.src[formTag] {
+		if val != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// keys returns the index of the source keys, building it on first use.
+func (d *decodeState) keys() *keyIndex {
+	if d.index == nil {
+		d.index = newKeyIndex(d.src, d.opts.pathSyntax)
+		for key, headers := range d.files {
+			if len(headers) > 0 {
+				d.index.add(key)
+			}
+		}
+	}
+
+	return d.index
+}
+
+// isTextUnmarshaler reports whether the value, or a pointer to the value, implements encoding.TextUnmarshaler.
+func isTextUnmarshaler(val reflect.Value) bool {
+	return val.Type().Implements(textUnmarshalerType) ||
+		(val.CanAddr() && val.Addr().Type().Implements(textUnmarshalerType))
+}
+
 // ensurePointerIsSet checks if the provided value is a nil pointer, and sets the internal value if the value is nil.
 func ensurePointerIsSet(val reflect.Value) {
 	if val.Kind() == reflect.Pointer && val.IsNil() {
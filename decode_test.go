@@ -1,8 +1,14 @@
 package form
 
 import (
+	"errors"
 	"fmt"
+	"net/netip"
 	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -66,6 +72,53 @@ type FormStructNested struct {
 	NestedInt    int    `form:"nestedInt,omitempty"`
 }
 
+type Address struct {
+	Street string `form:"street,omitempty"`
+	City   string `form:"city,omitempty"`
+}
+
+type CheckoutForm struct {
+	Email    string   `form:"email,omitempty"`
+	Billing  Address  `form:"billing,omitempty"`
+	Shipping *Address `form:"shipping,omitempty"`
+}
+
+type LineItem struct {
+	SKU string `form:"sku,omitempty"`
+	Qty int    `form:"qty,omitempty"`
+}
+
+type OrderForm struct {
+	Items     []LineItem  `form:"items,omitempty"`
+	ItemPtrs  []*LineItem `form:"itemPtrs,omitempty"`
+	Addresses []Address   `form:"addresses,omitempty"`
+}
+
+type NestedMapForm struct {
+	Addresses map[string]Address              `form:"addresses,omitempty"`
+	Matrix    map[string]map[string]int       `form:"matrix,omitempty"`
+	Items     map[string][]LineItem           `form:"items,omitempty"`
+	Deep      map[string]map[string][]Address `form:"deep,omitempty"`
+	Meta      map[string]string               `form:"meta,omitempty"`
+}
+
+type TypedKeyForm struct {
+	IntKeys  map[int]string        `form:"intKeys,omitempty"`
+	UintKeys map[uint8][]int       `form:"uintKeys,omitempty"`
+	BoolKeys map[bool]string       `form:"boolKeys,omitempty"`
+	TextKeys map[netip.Addr]string `form:"textKeys,omitempty"`
+}
+
+type TaggedForm struct {
+	Name      string   `schema:"name" json:"fullName"`
+	Email     string   `json:"email,omitempty"`
+	Nickname  string   `schema:",omitempty"`
+	UserID    int      `form:"id"`
+	Ignored   string   `schema:"-" json:"ignored"`
+	HomePlace *Address `json:"home"`
+	CreatedBy string
+}
+
 type TruckSettingsInput struct {
 	Location         *string `form:"location"` // Eventually will become structured, once we have location services
 	OpenTime         *string `form:"openTime"`
@@ -176,6 +229,36 @@ func TestUnmarshal(t *testing.T) {
 				},
 			},
 		},
+		{
+			in: In{
+				name: "success -- nested struct",
+				FormData: url.Values{
+					"nestedStruct[nestedString]": []string{"nested"},
+					"nestedStruct[nestedInt]":    []string{"3"},
+					"nestedString":               []string{"not nested"},
+				},
+			},
+			out: Out{
+				Resp: FormStruct{
+					NestedStruct: FormStructNested{
+						NestedString: "nested",
+						NestedInt:    3,
+					},
+				},
+			},
+		},
+		{
+			in: In{
+				name: "failure -- bad nested int param",
+				FormData: url.Values{
+					"nestedStruct[nestedInt]": []string{"three"},
+				},
+			},
+			out: Out{
+				Resp: FormStruct{},
+				Err:  "Unable to decode tag 'nestedStruct[nestedInt]': strconv.ParseInt: parsing \"three\": invalid syntax",
+			},
+		},
 		{
 			in: In{
 				name: "failure -- bad int param",
@@ -257,7 +340,7 @@ func TestUnmarshal(t *testing.T) {
 			},
 			out: Out{
 				Resp: FormStruct{},
-				Err:  "Unable to decode tag 'map_string_int': error decoding map value: Unable to decode tag 'map_string_int': strconv.ParseInt: parsing \"not an int\": invalid syntax",
+				Err:  "Unable to decode tag 'map_string_int[keyOne]': strconv.ParseInt: parsing \"not an int\": invalid syntax",
 			},
 		},
 		{
@@ -269,7 +352,7 @@ func TestUnmarshal(t *testing.T) {
 			},
 			out: Out{
 				Resp: FormStruct{},
-				Err:  "Unable to decode tag 'map_string_int_slice': error decoding map slice: Unable to decode tag 'map_string_int_slice': strconv.ParseInt: parsing \"not an int\": invalid syntax",
+				Err:  "Unable to decode tag 'map_string_int_slice[keyOne]': strconv.ParseInt: parsing \"not an int\": invalid syntax",
 			},
 		},
 	}
@@ -308,6 +391,1024 @@ func TestUnmarshal_StructPointer(t *testing.T) {
 	assert.Equal(t, input["stringParam"][0], val.StringParam, "expected stringParams to equal")
 }
 
+func TestUnmarshal_NestedPathSyntax(t *testing.T) {
+	tests := []struct {
+		name     string
+		syntax   PathSyntax
+		formData url.Values
+		expected CheckoutForm
+	}{
+		{
+			name:   "bracket syntax",
+			syntax: PathBracket,
+			formData: url.Values{
+				"email":            []string{"a@example.com"},
+				"billing[street]":  []string{"1 Main St"},
+				"billing[city]":    []string{"Ullapool"},
+				"shipping[street]": []string{"2 Side St"},
+				"shipping[city]":   []string{"Inverness"},
+				"billing.city":     []string{"ignored"},
+			},
+			expected: CheckoutForm{
+				Email:    "a@example.com",
+				Billing:  Address{Street: "1 Main St", City: "Ullapool"},
+				Shipping: &Address{Street: "2 Side St", City: "Inverness"},
+			},
+		},
+		{
+			name:   "dot syntax",
+			syntax: PathDot,
+			formData: url.Values{
+				"billing.street":  []string{"1 Main St"},
+				"billing.city":    []string{"Ullapool"},
+				"shipping.street": []string{"2 Side St"},
+				"shipping.city":   []string{"Inverness"},
+				"billing[city]":   []string{"ignored"},
+			},
+			expected: CheckoutForm{
+				Billing:  Address{Street: "1 Main St", City: "Ullapool"},
+				Shipping: &Address{Street: "2 Side St", City: "Inverness"},
+			},
+		},
+		{
+			name:   "absent nested struct pointer is left nil",
+			syntax: PathBracket,
+			formData: url.Values{
+				"billing[city]": []string{"Ullapool"},
+				"city":          []string{"ignored"},
+			},
+			expected: CheckoutForm{
+				Billing: Address{City: "Ullapool"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checkout CheckoutForm
+			err := Unmarshal(tt.formData, &checkout, WithPathSyntax(tt.syntax))
+			assert.NoError(t, err, "unexpected error")
+			assert.Equal(t, tt.expected, checkout, "expected equal form struct")
+		})
+	}
+}
+
+func TestUnmarshal_IndexedSlice(t *testing.T) {
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected OrderForm
+		err      string
+	}{
+		{
+			name: "success -- slices of structs",
+			formData: url.Values{
+				"items[0][sku]":        []string{"A-1"},
+				"items[0][qty]":        []string{"2"},
+				"items[1][sku]":        []string{"B-2"},
+				"items[1][qty]":        []string{"5"},
+				"itemPtrs[0][sku]":     []string{"C-3"},
+				"addresses[0][city]":   []string{"Ullapool"},
+				"addresses[1][street]": []string{"2 Side St"},
+			},
+			expected: OrderForm{
+				Items:     []LineItem{{SKU: "A-1", Qty: 2}, {SKU: "B-2", Qty: 5}},
+				ItemPtrs:  []*LineItem{{SKU: "C-3"}},
+				Addresses: []Address{{City: "Ullapool"}, {Street: "2 Side St"}},
+			},
+		},
+		{
+			name: "success -- sparse indexes are decoded in index order",
+			formData: url.Values{
+				"items[10][sku]": []string{"C-3"},
+				"items[2][sku]":  []string{"B-2"},
+				"items[0][sku]":  []string{"A-1"},
+			},
+			expected: OrderForm{
+				Items: []LineItem{{SKU: "A-1"}, {SKU: "B-2"}, {SKU: "C-3"}},
+			},
+		},
+		{
+			name: "failure -- non-numeric index",
+			formData: url.Values{
+				"items[first][sku]": []string{"A-1"},
+			},
+			err: "Unable to decode tag 'items': invalid slice index \"first\"",
+		},
+		{
+			name: "failure -- non-canonical index",
+			formData: url.Values{
+				"items[01][sku]": []string{"A-1"},
+			},
+			err: "Unable to decode tag 'items': invalid slice index \"01\"",
+		},
+		{
+			name: "failure -- bad element value",
+			formData: url.Values{
+				"items[0][qty]": []string{"two"},
+			},
+			err: "Unable to decode tag 'items[0][qty]': strconv.ParseInt: parsing \"two\": invalid syntax",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var order OrderForm
+			err := Unmarshal(tt.formData, &order)
+			if tt.err == "" {
+				assert.NoError(t, err, "unexpected error")
+				assert.Equal(t, tt.expected, order, "expected equal form struct")
+			} else {
+				assert.ErrorContains(t, err, tt.err, "expected equal errors")
+			}
+		})
+	}
+}
+
+func TestUnmarshal_NestedMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected NestedMapForm
+		err      string
+	}{
+		{
+			name: "success -- nested maps",
+			formData: url.Values{
+				"addresses[home][city]":      []string{"Ullapool"},
+				"addresses[work][street]":    []string{"2 Side St"},
+				"matrix[a][b]":               []string{"1"},
+				"matrix[a][c]":               []string{"2"},
+				"matrix[d][e]":               []string{"3"},
+				"items[open][0][sku]":        []string{"A-1"},
+				"items[open][1][sku]":        []string{"B-2"},
+				"items[closed][0][qty]":      []string{"4"},
+				"deep[x][y][0][city]":        []string{"Inverness"},
+				"meta[key]":                  []string{"value"},
+				"meta[a][b]":                 []string{"not a map value"},
+				"addresses[home]":            []string{"not a struct"},
+				"addresses[unclosed[city]]":  []string{"malformed"},
+				"addresses[unterminated[cit": []string{"malformed"},
+			},
+			expected: NestedMapForm{
+				Addresses: map[string]Address{
+					"home": {City: "Ullapool"},
+					"work": {Street: "2 Side St"},
+				},
+				Matrix: map[string]map[string]int{
+					"a": {"b": 1, "c": 2},
+					"d": {"e": 3},
+				},
+				Items: map[string][]LineItem{
+					"open":   {{SKU: "A-1"}, {SKU: "B-2"}},
+					"closed": {{Qty: 4}},
+				},
+				Deep: map[string]map[string][]Address{
+					"x": {"y": {{City: "Inverness"}}},
+				},
+				Meta: map[string]string{
+					"key": "value",
+				},
+			},
+		},
+		{
+			name: "failure -- bad nested map value",
+			formData: url.Values{
+				"matrix[a][b]": []string{"one"},
+			},
+			err: "Unable to decode tag 'matrix[a][b]': strconv.ParseInt: parsing \"one\": invalid syntax",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var nested NestedMapForm
+			err := Unmarshal(tt.formData, &nested)
+			if tt.err == "" {
+				assert.NoError(t, err, "unexpected error")
+				assert.Equal(t, tt.expected, nested, "expected equal form struct")
+			} else {
+				assert.ErrorContains(t, err, tt.err, "expected equal errors")
+			}
+		})
+	}
+}
+
+func TestUnmarshal_TypedMapKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected TypedKeyForm
+		err      string
+	}{
+		{
+			name: "success -- typed keys",
+			formData: url.Values{
+				"intKeys[1]":          []string{"one"},
+				"intKeys[-2]":         []string{"minus two"},
+				"uintKeys[3]":         []string{"4", "5"},
+				"boolKeys[true]":      []string{"yes"},
+				"textKeys[127.0.0.1]": []string{"localhost"},
+			},
+			expected: TypedKeyForm{
+				IntKeys:  map[int]string{1: "one", -2: "minus two"},
+				UintKeys: map[uint8][]int{3: {4, 5}},
+				BoolKeys: map[bool]string{true: "yes"},
+				TextKeys: map[netip.Addr]string{netip.MustParseAddr("127.0.0.1"): "localhost"},
+			},
+		},
+		{
+			name: "failure -- bad int key",
+			formData: url.Values{
+				"intKeys[one]": []string{"one"},
+			},
+			err: "Unable to decode tag 'intKeys[one]': strconv.ParseInt: parsing \"one\": invalid syntax",
+		},
+		{
+			name: "failure -- uint key out of range",
+			formData: url.Values{
+				"uintKeys[256]": []string{"1"},
+			},
+			err: "Unable to decode tag 'uintKeys[256]': strconv.ParseUint: parsing \"256\": value out of range",
+		},
+		{
+			name: "failure -- bad text key",
+			formData: url.Values{
+				"textKeys[localhost]": []string{"localhost"},
+			},
+			err: "Unable to decode tag 'textKeys[localhost]': ParseAddr(\"localhost\"): unable to parse IP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var typed TypedKeyForm
+			err := Unmarshal(tt.formData, &typed)
+			if tt.err == "" {
+				assert.NoError(t, err, "unexpected error")
+				assert.Equal(t, tt.expected, typed, "expected equal form struct")
+			} else {
+				assert.ErrorContains(t, err, tt.err, "expected equal errors")
+			}
+		})
+	}
+}
+
+func TestUnmarshal_AllErrors(t *testing.T) {
+	formData := url.Values{
+		"stringParam":             []string{"valid"},
+		"int_param":               []string{"two"},
+		"bool_param":              []string{"maybe"},
+		"slice_int_ptr_param":     []string{"1", "x", "3"},
+		"map_string_int[a]":       []string{"1"},
+		"map_string_int[b]":       []string{"b"},
+		"nestedStruct[nestedInt]": []string{"three"},
+	}
+
+	var formStruct FormStruct
+	err := Unmarshal(formData, &formStruct, WithAllErrors())
+
+	var decodeErrs DecodeErrors
+	assert.True(t, errors.As(err, &decodeErrs), "expected DecodeErrors")
+	assert.Len(t, decodeErrs, 5, "expected an error for each bad value")
+
+	paths := map[string]ErrorDecode{}
+	for _, decodeErr := range decodeErrs {
+		decodeErr.Err = nil
+		paths[decodeErr.Path] = decodeErr
+	}
+	formStructType := reflect.TypeOf(FormStruct{})
+	nestedType := reflect.TypeOf(FormStructNested{})
+	assert.Equal(t, map[string]ErrorDecode{
+		"int_param":               {Path: "int_param", Field: "IntParam", Struct: formStructType, Value: "two"},
+		"bool_param":              {Path: "bool_param", Field: "BoolParam", Struct: formStructType, Value: "maybe"},
+		"slice_int_ptr_param":     {Path: "slice_int_ptr_param", Field: "SliceIntPtrParam", Struct: formStructType, Value: "x", Index: "1"},
+		"map_string_int[b]":       {Path: "map_string_int[b]", Field: "MapStringInt", Struct: formStructType, Value: "b", Index: "b"},
+		"nestedStruct[nestedInt]": {Path: "nestedStruct[nestedInt]", Field: "NestedInt", Struct: nestedType, Value: "three"},
+	}, paths, "expected error details for each bad value")
+
+	assert.ErrorContains(t, err, "Unable to decode tag 'int_param': strconv.ParseInt: parsing \"two\": invalid syntax")
+	assert.ErrorContains(t, err, "Unable to decode tag 'bool_param': strconv.ParseBool: parsing \"maybe\": invalid syntax")
+
+	// Valid values are still decoded
+	assert.Equal(t, "valid", formStruct.StringParam, "expected valid values to decode")
+	assert.Equal(t, []*int{toPtr(1), toPtr(3)}, formStruct.SliceIntPtrParam, "expected valid values to decode")
+	assert.Equal(t, map[string]int{"a": 1}, formStruct.MapStringInt, "expected valid values to decode")
+}
+
+func TestUnmarshal_FirstError(t *testing.T) {
+	formData := url.Values{
+		"items[0][qty]": []string{"two"},
+	}
+
+	var order OrderForm
+	err := Unmarshal(formData, &order)
+
+	var decodeErr ErrorDecode
+	assert.True(t, errors.As(err, &decodeErr), "expected ErrorDecode")
+	assert.Equal(t, "items[0][qty]", decodeErr.Path, "expected full form key")
+	assert.Equal(t, "Qty", decodeErr.Field, "expected struct field name")
+	assert.Equal(t, reflect.TypeOf(LineItem{}), decodeErr.Struct, "expected struct type")
+	assert.Equal(t, "two", decodeErr.Value, "expected raw form value")
+	assert.Equal(t, "0", decodeErr.Index, "expected slice index")
+	assert.ErrorIs(t, err, strconv.ErrSyntax, "expected wrapped strconv error")
+}
+
+func TestUnmarshal_SentinelErrors(t *testing.T) {
+	type UnsupportedForm struct {
+		Channel chan int `form:"channel"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		dest     any
+		sentinel error
+		cause    error
+	}{
+		{
+			name:     "int overflow",
+			formData: url.Values{"int64_param": []string{"9223372036854775808"}},
+			dest:     &FormStruct{},
+			sentinel: ErrOverflow,
+			cause:    strconv.ErrRange,
+		},
+		{
+			name:     "uint overflow in map key",
+			formData: url.Values{"uintKeys[256]": []string{"1"}},
+			dest:     &TypedKeyForm{},
+			sentinel: ErrOverflow,
+			cause:    strconv.ErrRange,
+		},
+		{
+			name:     "unsupported type",
+			formData: url.Values{"channel": []string{"1"}},
+			dest:     &UnsupportedForm{},
+			sentinel: ErrUnsupportedType,
+		},
+		{
+			name:     "invalid destination",
+			formData: url.Values{},
+			dest:     FormStruct{},
+			sentinel: ErrInvalidDestination,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal(tt.formData, tt.dest)
+			assert.ErrorIs(t, err, tt.sentinel, "expected sentinel error")
+			if tt.cause != nil {
+				assert.ErrorIs(t, err, tt.cause, "expected wrapped cause")
+			}
+		})
+	}
+
+	err := Unmarshal(url.Values{"int_param": []string{"two"}}, &FormStruct{})
+	assert.NotErrorIs(t, err, ErrOverflow, "expected syntax errors not to match ErrOverflow")
+}
+
+func TestDecoder_Concurrent(t *testing.T) {
+	decoder := NewDecoder(WithAllErrors())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			formData := url.Values{
+				"items[0][sku]": []string{"sku-" + strconv.Itoa(i)},
+				"items[0][qty]": []string{strconv.Itoa(i)},
+			}
+			if i%2 == 1 {
+				formData["items[1][qty]"] = []string{"bad"}
+			}
+
+			var order OrderForm
+			err := decoder.Decode(formData, &order)
+			assert.Equal(t, LineItem{SKU: "sku-" + strconv.Itoa(i), Qty: i}, order.Items[0], "expected decoded item")
+
+			// Errors from one decode must not leak into another
+			var decodeErrs DecodeErrors
+			if i%2 == 1 {
+				assert.True(t, errors.As(err, &decodeErrs), "expected DecodeErrors")
+				assert.Len(t, decodeErrs, 1, "expected a single error")
+			} else {
+				assert.NoError(t, err, "unexpected error")
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestUnmarshal_TagNames(t *testing.T) {
+	formData := url.Values{
+		"name":             []string{"Tavish"},
+		"fullName":         []string{"Tavish DeGroot"},
+		"email":            []string{"t@example.com"},
+		"nickname":         []string{"demo"},
+		"id":               []string{"4"},
+		"ignored":          []string{"ignored"},
+		"home[city]":       []string{"Ullapool"},
+		"created_by":       []string{"admin"},
+		"home_place[city]": []string{"ignored"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected TaggedForm
+	}{
+		{
+			name:     "default form tag",
+			expected: TaggedForm{UserID: 4},
+		},
+		{
+			name: "tag fallback chain",
+			opts: []Option{WithTagNames("schema", "json", "form")},
+			expected: TaggedForm{
+				Name:      "Tavish",
+				Email:     "t@example.com",
+				UserID:    4,
+				HomePlace: &Address{City: "Ullapool"},
+			},
+		},
+		{
+			name: "naming strategy for untagged fields",
+			opts: []Option{WithTagNames("schema", "json", "form"), WithNaming(NamingSnakeCase)},
+			expected: TaggedForm{
+				Name:      "Tavish",
+				Email:     "t@example.com",
+				Nickname:  "demo",
+				UserID:    4,
+				HomePlace: &Address{City: "Ullapool"},
+				CreatedBy: "admin",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tagged TaggedForm
+			err := Unmarshal(formData, &tagged, tt.opts...)
+			assert.NoError(t, err, "unexpected error")
+			assert.Equal(t, tt.expected, tagged, "expected equal form struct")
+		})
+	}
+}
+
+func TestUnmarshal_Strict(t *testing.T) {
+	tests := []struct {
+		name         string
+		formData     url.Values
+		opts         []Option
+		expectedKeys []string
+	}{
+		{
+			name: "known keys",
+			formData: url.Values{
+				"email":                 []string{"a@example.com"},
+				"billing[city]":         []string{"Ullapool"},
+				"shipping[street]":      []string{"1 Shore St"},
+				"addresses[home][city]": []string{"Inverness"},
+			},
+			opts: []Option{WithStrict()},
+		},
+		{
+			name: "unknown keys",
+			formData: url.Values{
+				"email":                  []string{"a@example.com"},
+				"city":                   []string{"Ullapool"},
+				"billing[country]":       []string{"UK"},
+				"addresses[home][city]":  []string{"Inverness"},
+				"addresses[home][zip]":   []string{"IV1"},
+				"addresses[home][a][b]":  []string{"c"},
+				"csrf_token":             []string{"abc"},
+				"unset":                  []string{},
+				"billing[street][extra]": []string{"x"},
+			},
+			opts:         []Option{WithStrict()},
+			expectedKeys: []string{"addresses[home][a][b]", "addresses[home][zip]", "billing[country]", "billing[street][extra]", "city", "csrf_token"},
+		},
+		{
+			name: "allowed keys",
+			formData: url.Values{
+				"email":      []string{"a@example.com"},
+				"csrf_token": []string{"abc"},
+				"submit":     []string{"Save"},
+				"city":       []string{"Ullapool"},
+			},
+			opts:         []Option{WithStrict("csrf_token", "submit")},
+			expectedKeys: []string{"city"},
+		},
+		{
+			name: "aggregated with decode errors",
+			formData: url.Values{
+				"count": []string{"two"},
+				"city":  []string{"Ullapool"},
+			},
+			opts:         []Option{WithStrict(), WithAllErrors()},
+			expectedKeys: []string{"count", "city"},
+		},
+		{
+			name: "keys under invalid map keys and indexes",
+			formData: url.Values{
+				"limits[x]":       []string{"y"},
+				"limits[1]":       []string{"z"},
+				"items[01][city]": []string{"Ullapool"},
+				"items[0][city]":  []string{"Inverness"},
+			},
+			opts:         []Option{WithStrict(), WithAllErrors()},
+			expectedKeys: []string{"limits[x]", "items"},
+		},
+	}
+
+	type StrictForm struct {
+		Email     string             `form:"email"`
+		Count     int                `form:"count"`
+		Billing   Address            `form:"billing"`
+		Shipping  *Address           `form:"shipping"`
+		Addresses map[string]Address `form:"addresses"`
+		Limits    map[int]string     `form:"limits"`
+		Items     []Address          `form:"items"`
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest StrictForm
+			err := Unmarshal(tt.formData, &dest, tt.opts...)
+			if tt.expectedKeys == nil {
+				assert.NoError(t, err, "expected no error")
+				return
+			}
+
+			var decodeErrs DecodeErrors
+			assert.True(t, errors.As(err, &decodeErrs), "expected DecodeErrors")
+
+			var keys []string
+			for _, decodeErr := range decodeErrs {
+				keys = append(keys, decodeErr.Path)
+			}
+			assert.Equal(t, tt.expectedKeys, keys, "expected an error for each unknown key")
+		})
+	}
+
+	err := Unmarshal(url.Values{"city": []string{"Ullapool"}, "city[extra]": []string{"x"}}, &Address{}, WithStrict())
+	assert.ErrorIs(t, err, ErrUnknownKey, "expected ErrUnknownKey")
+	assert.EqualError(t, err, "Unable to decode tag 'city[extra]': unknown form key")
+}
+
+func TestUnmarshal_Duplicates(t *testing.T) {
+	type PaymentForm struct {
+		Account   string            `form:"account"`
+		Amount    int               `form:"amount,dup=error"`
+		Reference string            `form:"reference,dup=last"`
+		Tags      string            `form:"tags,dup=join"`
+		Notes     []string          `form:"notes"`
+		Payee     Address           `form:"payee,dup=error"`
+		Meta      map[string]string `form:"meta,dup=last"`
+	}
+
+	formData := url.Values{
+		"account":     []string{"1", "2"},
+		"reference":   []string{"a", "b"},
+		"tags":        []string{"x", "y"},
+		"notes":       []string{"one", "two"},
+		"payee[city]": []string{"Ullapool"},
+		"meta[key]":   []string{"first", "last"},
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		opts     []Option
+		expected PaymentForm
+		errPath  string
+	}{
+		{
+			name:     "field policies",
+			formData: formData,
+			expected: PaymentForm{
+				Account:   "1",
+				Reference: "b",
+				Tags:      "x,y",
+				Notes:     []string{"one", "two"},
+				Payee:     Address{City: "Ullapool"},
+				Meta:      map[string]string{"key": "last"},
+			},
+		},
+		{
+			name:     "decoder policy",
+			formData: formData,
+			opts:     []Option{WithDuplicates(DuplicateLast)},
+			expected: PaymentForm{
+				Account:   "2",
+				Reference: "b",
+				Tags:      "x,y",
+				Notes:     []string{"one", "two"},
+				Payee:     Address{City: "Ullapool"},
+				Meta:      map[string]string{"key": "last"},
+			},
+		},
+		{
+			name:     "field error",
+			formData: url.Values{"amount": []string{"10", "1000"}},
+			errPath:  "amount",
+		},
+		{
+			name:     "nested field error",
+			formData: url.Values{"payee[city]": []string{"Ullapool", "Inverness"}},
+			errPath:  "payee[city]",
+		},
+		{
+			name:     "decoder error",
+			formData: url.Values{"account": []string{"1", "2"}},
+			opts:     []Option{WithDuplicates(DuplicateError)},
+			errPath:  "account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest PaymentForm
+			err := Unmarshal(tt.formData, &dest, tt.opts...)
+			if tt.errPath == "" {
+				assert.NoError(t, err, "expected no error")
+				assert.Equal(t, tt.expected, dest, "expected decoded values")
+				return
+			}
+
+			var decodeErr ErrorDecode
+			assert.True(t, errors.As(err, &decodeErr), "expected ErrorDecode")
+			assert.Equal(t, tt.errPath, decodeErr.Path, "expected full form key")
+			assert.ErrorIs(t, err, ErrDuplicateValue, "expected ErrDuplicateValue")
+		})
+	}
+}
+
+func TestUnmarshal_InvalidTag(t *testing.T) {
+	type InvalidTagForm struct {
+		Name   string `form:"name"`
+		Amount int    `form:"amount,dup=sometimes"`
+	}
+
+	var dest InvalidTagForm
+	err := Unmarshal(url.Values{"name": []string{"Tavish"}}, &dest)
+
+	var decodeErr ErrorDecode
+	assert.True(t, errors.As(err, &decodeErr), "expected ErrorDecode")
+	assert.Equal(t, "amount", decodeErr.Path, "expected full form key")
+	assert.Equal(t, "Amount", decodeErr.Field, "expected struct field name")
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+
+	_, err = Marshal(dest)
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag when encoding")
+
+	// Slices receive every value, so a duplicate policy would have no effect
+	type SliceDup struct {
+		N     []int      `form:"n,dup=error"`
+		Items []LineItem `form:"items,dup=last"`
+	}
+	err = Unmarshal(url.Values{"n": []string{"1", "2"}}, &SliceDup{}, WithAllErrors())
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+
+	var decodeErrs DecodeErrors
+	assert.True(t, errors.As(err, &decodeErrs), "expected DecodeErrors")
+	assert.Len(t, decodeErrs, 2, "expected an error for each slice field")
+}
+
+type Paging struct {
+	Size  int    `form:"size,default=10"`
+	Order string `form:"order,default=asc"`
+}
+
+func TestUnmarshal_Defaults(t *testing.T) {
+	type Results struct {
+		Paging Paging `form:"paging"`
+	}
+	type SearchForm struct {
+		Query    string         `form:"q"`
+		Page     int            `form:"page,default=1"`
+		PerPage  *uint          `form:"per_page,omitempty,default=20"`
+		Sort     []string       `form:"sort,default=date|name"`
+		Since    time.Time      `form:"since,default=2024-08-19T05:09:00Z"`
+		Timeout  time.Duration  `form:"timeout,default=5s"`
+		Host     netip.Addr     `form:"host,default=127.0.0.1"`
+		Filters  map[string]int `form:"filters"`
+		Location Address        `form:"location"`
+		Paging   Paging         `form:"paging"`
+		Results  Results        `form:"results"`
+		Next     *Paging        `form:"next"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected SearchForm
+	}{
+		{
+			name:     "absent keys",
+			formData: url.Values{"q": []string{"trucks"}},
+			expected: SearchForm{
+				Query:   "trucks",
+				Page:    1,
+				PerPage: toPtr(uint(20)),
+				Sort:    []string{"date", "name"},
+				Since:   MustParseTime("2024-08-19T05:09:00Z"),
+				Timeout: 5 * time.Second,
+				Host:    netip.MustParseAddr("127.0.0.1"),
+				Paging:  Paging{Size: 10, Order: "asc"},
+				Results: Results{Paging: Paging{Size: 10, Order: "asc"}},
+			},
+		},
+		{
+			name: "present keys",
+			formData: url.Values{
+				"page":                   []string{"3"},
+				"per_page":               []string{"50"},
+				"sort":                   []string{"price"},
+				"since":                  []string{"2023-01-01T00:00:00Z"},
+				"timeout":                []string{"1m"},
+				"host":                   []string{"10.0.0.1"},
+				"paging[size]":           []string{"25"},
+				"results[paging][order]": []string{"desc"},
+				"next[size]":             []string{"5"},
+			},
+			expected: SearchForm{
+				Page:    3,
+				PerPage: toPtr(uint(50)),
+				Sort:    []string{"price"},
+				Since:   MustParseTime("2023-01-01T00:00:00Z"),
+				Timeout: time.Minute,
+				Host:    netip.MustParseAddr("10.0.0.1"),
+				Paging:  Paging{Size: 25, Order: "asc"},
+				Results: Results{Paging: Paging{Size: 10, Order: "desc"}},
+				Next:    &Paging{Size: 5, Order: "asc"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest SearchForm
+			err := Unmarshal(tt.formData, &dest)
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, tt.expected, dest, "expected decoded values")
+
+			dest = SearchForm{}
+			err = NewStreamDecoder(strings.NewReader(tt.formData.Encode())).Decode(&dest)
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, tt.expected, dest, "expected decoded values from StreamDecoder")
+		})
+	}
+}
+
+func TestUnmarshal_InvalidDefault(t *testing.T) {
+	type BadIntDefault struct {
+		Page int `form:"page,default=one"`
+	}
+	type BadSliceDefault struct {
+		IDs []int `form:"ids,default=1|two"`
+	}
+	type BadMapDefault struct {
+		Meta map[string]string `form:"meta,default=a"`
+	}
+	type RequiredDefault struct {
+		Page int `form:"page,required,default=1"`
+	}
+	type CommaDefault struct {
+		Sort string `form:"sort,default=date,name,omitempty"`
+	}
+
+	tests := []struct {
+		name  string
+		dest  any
+		cause error
+	}{
+		{name: "int", dest: &BadIntDefault{}, cause: strconv.ErrSyntax},
+		{name: "slice element", dest: &BadSliceDefault{}, cause: strconv.ErrSyntax},
+		{name: "unsupported kind", dest: &BadMapDefault{}},
+		{name: "required field", dest: &RequiredDefault{}},
+		{name: "comma in default", dest: &CommaDefault{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Defaults are checked even when the field's key is present
+			err := Unmarshal(url.Values{"page": []string{"1"}, "ids": []string{"1"}}, tt.dest)
+			assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+			if tt.cause != nil {
+				assert.ErrorIs(t, err, tt.cause, "expected wrapped cause")
+			}
+		})
+	}
+}
+
+// assertMissing asserts that the error reports a missing value for each of the form keys, in order.
+func assertMissing(t *testing.T, err error, expectedPaths []string) {
+	t.Helper()
+	if expectedPaths == nil {
+		assert.NoError(t, err, "expected no error")
+		return
+	}
+
+	var decodeErrs DecodeErrors
+	assert.True(t, errors.As(err, &decodeErrs), "expected DecodeErrors")
+	assert.ErrorIs(t, err, ErrMissing, "expected ErrMissing")
+
+	var paths []string
+	for _, decodeErr := range decodeErrs {
+		assert.ErrorIs(t, decodeErr, ErrMissing, "expected only missing errors")
+		paths = append(paths, decodeErr.Path)
+	}
+	assert.Equal(t, expectedPaths, paths, "expected an error for each missing field")
+}
+
+func TestUnmarshal_Required(t *testing.T) {
+	type Contact struct {
+		Name  string `form:"name,required"`
+		Phone string `form:"phone"`
+	}
+
+	type Shipment struct {
+		Reference string             `form:"reference,required"`
+		Tags      []string           `form:"tags,required"`
+		Sender    Contact            `form:"sender,required"`
+		Return    Contact            `form:"return"`
+		Recipient *Contact           `form:"recipient"`
+		Parcels   []LineItem         `form:"parcels,required"`
+		Contacts  []Contact          `form:"contacts"`
+		Notify    map[string]Contact `form:"notify"`
+	}
+
+	tests := []struct {
+		name          string
+		formData      url.Values
+		expectedPaths []string
+	}{
+		{
+			name: "all present",
+			formData: url.Values{
+				"reference":          []string{"R1"},
+				"tags":               []string{"fragile"},
+				"sender[name]":       []string{"Tavish"},
+				"return[name]":       []string{"Tavish"},
+				"parcels[0][sku]":    []string{"A1"},
+				"contacts[0][name]":  []string{"Jane"},
+				"notify[home][name]": []string{"Jane"},
+			},
+		},
+		{
+			name:          "absent keys",
+			formData:      url.Values{},
+			expectedPaths: []string{"reference", "tags", "sender", "return[name]", "parcels"},
+		},
+		{
+			name: "empty values",
+			formData: url.Values{
+				"reference":       []string{""},
+				"tags":            []string{"", ""},
+				"sender[name]":    []string{""},
+				"parcels[0][sku]": []string{"A1"},
+			},
+			expectedPaths: []string{"reference", "tags", "sender[name]", "return[name]"},
+		},
+		{
+			name: "nested elements",
+			formData: url.Values{
+				"reference":           []string{"R1"},
+				"tags":                []string{"fragile"},
+				"sender[phone]":       []string{"555"},
+				"return[name]":        []string{"Tavish"},
+				"recipient[phone]":    []string{"555"},
+				"parcels[0][sku]":     []string{"A1"},
+				"contacts[0][name]":   []string{"Jane"},
+				"contacts[1][phone]":  []string{"555"},
+				"notify[work][phone]": []string{"555"},
+			},
+			expectedPaths: []string{"sender[name]", "recipient[name]", "contacts[1][name]", "notify[work][name]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest Shipment
+			err := Unmarshal(tt.formData, &dest, WithAllErrors())
+			assertMissing(t, err, tt.expectedPaths)
+
+			stream := NewStreamDecoder(strings.NewReader(tt.formData.Encode()), WithAllErrors())
+			err = stream.Decode(&Shipment{})
+			assertMissing(t, err, tt.expectedPaths)
+		})
+	}
+
+	var contact Contact
+	err := Unmarshal(url.Values{"phone": []string{"555"}}, &contact)
+	assert.Equal(t, ErrorDecode{Path: "name", Field: "Name", Struct: reflect.TypeOf(contact), Err: ErrMissing}, err)
+	assert.EqualError(t, err, "Unable to decode tag 'name': missing form value")
+}
+
+// Money is a struct type that does not implement encoding.TextUnmarshaler, standing in for types from other packages.
+type Money struct {
+	Cents int64 `form:"cents"`
+}
+
+func parseMoney(value string) (reflect.Value, error) {
+	whole, frac, _ := strings.Cut(value, ".")
+	cents, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || len(frac) != 2 {
+		return reflect.Value{}, fmt.Errorf("invalid amount %q", value)
+	}
+
+	return reflect.ValueOf(Money{Cents: cents}), nil
+}
+
+type InvoiceForm struct {
+	Total    Money            `form:"total"`
+	Discount *Money           `form:"discount"`
+	Lines    []Money          `form:"lines"`
+	Refunds  []*Money         `form:"refunds"`
+	Budgets  map[string]Money `form:"budgets"`
+	Tiers    map[Money]string `form:"tiers"`
+	Fallback Money            `form:"fallback,default=1.00"`
+}
+
+func TestDecoder_RegisterConverter(t *testing.T) {
+	decoder := NewDecoder()
+	decoder.RegisterConverter(reflect.TypeOf(Money{}), parseMoney)
+
+	formData := url.Values{
+		"total":        []string{"12.34"},
+		"discount":     []string{"0.50"},
+		"lines":        []string{"1.00", "2.50"},
+		"refunds":      []string{"0.25"},
+		"budgets[ops]": []string{"100.00"},
+		"tiers[5.00]":  []string{"gold"},
+	}
+
+	var invoice InvoiceForm
+	err := decoder.Decode(formData, &invoice)
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, InvoiceForm{
+		Total:    Money{Cents: 1234},
+		Discount: &Money{Cents: 50},
+		Lines:    []Money{{Cents: 100}, {Cents: 250}},
+		Refunds:  []*Money{{Cents: 25}},
+		Budgets:  map[string]Money{"ops": {Cents: 10000}},
+		Tiers:    map[Money]string{{Cents: 500}: "gold"},
+		Fallback: Money{Cents: 100},
+	}, invoice, "expected converted values")
+
+	err = decoder.Decode(url.Values{"lines": []string{"1.00", "one"}}, &InvoiceForm{})
+	var decodeErr ErrorDecode
+	assert.True(t, errors.As(err, &decodeErr), "expected ErrorDecode")
+	assert.Equal(t, ErrorDecode{
+		Path:   "lines",
+		Field:  "Lines",
+		Struct: reflect.TypeOf(InvoiceForm{}),
+		Value:  "one",
+		Index:  "1",
+		Err:    errors.New(`invalid amount "one"`),
+	}, decodeErr, "expected converter error details")
+
+	// Converters must return a value of the registered type
+	badDecoder := NewDecoder()
+	badDecoder.RegisterConverter(reflect.TypeOf(Money{}), func(value string) (reflect.Value, error) {
+		return reflect.ValueOf(value), nil
+	})
+	err = badDecoder.Decode(url.Values{"total": []string{"1.00"}}, &InvoiceForm{})
+	assert.ErrorContains(t, err, "converter for form.Money returned 1.00")
+
+	// Decoders without the converter are unaffected, and decode the type as a struct
+	var receipt struct {
+		Total Money `form:"total"`
+	}
+	err = Unmarshal(url.Values{"total[cents]": []string{"1234"}}, &receipt)
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, Money{Cents: 1234}, receipt.Total, "expected struct decoding")
+
+	// Struct metadata for a registry is cached in the registry, so Decoders created per request do not grow the shared
+	// caches
+	countEntries := func(cache *sync.Map) int {
+		n := 0
+		cache.Range(func(any, any) bool {
+			n++
+			return true
+		})
+		return n
+	}
+	structs, validations := countEntries(&structCache), countEntries(&validateCache)
+	for i := 0; i < 10; i++ {
+		perRequest := NewDecoder(WithValidation())
+		perRequest.RegisterConverter(reflect.TypeOf(Money{}), parseMoney)
+		err = perRequest.Decode(formData, &InvoiceForm{})
+		assert.NoError(t, err, "expected no error")
+	}
+	assert.Equal(t, structs, countEntries(&structCache), "expected no new shared struct cache entries")
+	assert.Equal(t, validations, countEntries(&validateCache), "expected no new shared validation cache entries")
+}
+
 func TestUnmarshal_NonStruct(t *testing.T) {
 	err := Unmarshal(url.Values{}, []string{})
 	assert.ErrorContains(t, err, "destination ([]string) must be a pointer to a struct", "unexpected error")
@@ -328,9 +1429,12 @@ func BenchmarkDecode(b *testing.B) {
 		"slice": {"one", "two", "three"},
 	}
 
+	// A Decoder holds only configuration, so a single instance is shared across decodes.
+	decoder := NewDecoder()
+
 	var benchmarkForm BenchmarkForm
 	for i := 0; i < b.N; i++ {
-		err := NewDecoder(formData).Decode(&benchmarkForm)
+		err := decoder.Decode(formData, &benchmarkForm)
 		if err != nil {
 			b.Fatalf("Unmarshal failed: %v", err)
 		}
@@ -407,9 +1511,11 @@ func BenchmarkDecodeLarge(b *testing.B) {
 		"twenty":    {"twenty"},
 	}
 
+	decoder := NewDecoder()
+
 	var benchmarkForm BenchmarkFormLarge
 	for i := 0; i < b.N; i++ {
-		err := NewDecoder(formData).Decode(&benchmarkForm)
+		err := decoder.Decode(formData, &benchmarkForm)
 		if err != nil {
 			b.Fatalf("Unmarshal failed: %v", err)
 		}
@@ -454,3 +1560,109 @@ func BenchmarkGorillaSchemaDecodeLarge(b *testing.B) {
 	}
 	_ = fmt.Sprintf("%v", benchmarkForm)
 }
+
+type BenchmarkFormNested struct {
+	Email    string            `form:"email"`
+	Billing  Address           `form:"billing"`
+	Shipping *Address          `form:"shipping"`
+	Items    []LineItem        `form:"items"`
+	Meta     map[string]string `form:"meta"`
+}
+
+func BenchmarkDecodeNested(b *testing.B) {
+	formData := map[string][]string{
+		"email":            {"a@example.com"},
+		"billing[street]":  {"1 Main St"},
+		"billing[city]":    {"Ullapool"},
+		"shipping[street]": {"2 Side St"},
+		"shipping[city]":   {"Inverness"},
+		"items[0][sku]":    {"A-1"},
+		"items[0][qty]":    {"2"},
+		"items[1][sku]":    {"B-2"},
+		"items[1][qty]":    {"5"},
+		"meta[source]":     {"web"},
+	}
+
+	decoder := NewDecoder()
+
+	var benchmarkForm BenchmarkFormNested
+	for i := 0; i < b.N; i++ {
+		err := decoder.Decode(formData, &benchmarkForm)
+		if err != nil {
+			b.Fatalf("Unmarshal failed: %v", err)
+		}
+	}
+	_ = fmt.Sprintf("%v", benchmarkForm)
+}
+
+func BenchmarkDecodeLargeParallel(b *testing.B) {
+	formData := map[string][]string{
+		"one":       {"one"},
+		"two":       {"two"},
+		"three":     {"three"},
+		"four":      {"four"},
+		"five":      {"five"},
+		"six":       {"six"},
+		"seven":     {"seven"},
+		"eight":     {"eight"},
+		"nine":      {"nine"},
+		"ten":       {"ten"},
+		"eleven":    {"eleven"},
+		"twelve":    {"twelve"},
+		"thirteen":  {"thirteen"},
+		"fourteen":  {"fourteen"},
+		"fifteen":   {"fifteen"},
+		"sixteen":   {"sixteen"},
+		"seventeen": {"seventeen"},
+		"eighteen":  {"eighteen"},
+		"nineteen":  {"nineteen"},
+		"twenty":    {"twenty"},
+	}
+
+	// Field metadata is cached per type and shared by every Decoder, so parallel decodes only read from the cache.
+	decoder := NewDecoder()
+	b.RunParallel(func(pb *testing.PB) {
+		var benchmarkForm BenchmarkFormLarge
+		for pb.Next() {
+			err := decoder.Decode(formData, &benchmarkForm)
+			if err != nil {
+				b.Fatalf("Unmarshal failed: %v", err)
+			}
+		}
+	})
+}
+
+type BenchmarkFormManyKeys struct {
+	Labels   map[string]string   `form:"labels"`
+	Counts   map[string]int      `form:"counts"`
+	Tags     map[string][]string `form:"tags"`
+	Items    []LineItem          `form:"items"`
+	Billing  Address             `form:"billing"`
+	Shipping *Address            `form:"shipping"`
+}
+
+func BenchmarkDecodeManyKeys(b *testing.B) {
+	formData := map[string][]string{
+		"billing[city]":  {"Ullapool"},
+		"shipping[city]": {"Inverness"},
+	}
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		formData["labels["+key+"]"] = []string{"label"}
+		formData["counts["+key+"]"] = []string{key}
+		formData["items["+key+"][sku]"] = []string{"sku-" + key}
+		formData["items["+key+"][qty]"] = []string{key}
+		formData["ignored["+key+"]"] = []string{"ignored"}
+	}
+
+	decoder := NewDecoder()
+
+	var benchmarkForm BenchmarkFormManyKeys
+	for i := 0; i < b.N; i++ {
+		err := decoder.Decode(formData, &benchmarkForm)
+		if err != nil {
+			b.Fatalf("Unmarshal failed: %v", err)
+		}
+	}
+	_ = fmt.Sprintf("%v", benchmarkForm)
+}
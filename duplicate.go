@@ -0,0 +1,61 @@
+package form
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DuplicatePolicy controls how a field that holds a single value is decoded when its form key has multiple values.
+// Slice fields always receive every value.
+type DuplicatePolicy int
+
+const (
+	// DuplicateFirst decodes the first value and ignores the rest. This is the default.
+	DuplicateFirst DuplicatePolicy = iota + 1
+
+	// DuplicateLast decodes the last value and ignores the rest.
+	DuplicateLast
+
+	// DuplicateError fails to decode the field with an error wrapping ErrDuplicateValue.
+	DuplicateError
+
+	// DuplicateJoin joins the values with commas and decodes the result: `a,b`.
+	DuplicateJoin
+)
+
+// parseDuplicatePolicy returns the policy for the value of a `dup=` tag option.
+func parseDuplicatePolicy(name string) (DuplicatePolicy, error) {
+	switch name {
+	case "first":
+		return DuplicateFirst, nil
+	case "last":
+		return DuplicateLast, nil
+	case "error":
+		return DuplicateError, nil
+	case "join":
+		return DuplicateJoin, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown duplicate policy %q", ErrInvalidTag, name)
+	}
+}
+
+// resolve returns the single value to decode from the form values for a key.
+func (p DuplicatePolicy) resolve(values []string) (string, error) {
+	if len(values) == 1 {
+		return values[0], nil
+	}
+
+	switch p {
+	case DuplicateLast:
+		return values[len(values)-1], nil
+
+	case DuplicateError:
+		return "", fmt.Errorf("%w: received %d values", ErrDuplicateValue, len(values))
+
+	case DuplicateJoin:
+		return strings.Join(values, ","), nil
+
+	default:
+		return values[0], nil
+	}
+}
@@ -1,11 +1,15 @@
 package form
 
 import (
+	"cmp"
 	"encoding"
 	"fmt"
+	"net/url"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -17,17 +21,6 @@ func toPtr[T any](val T) *T {
 	return &val
 }
 
-// ErrorEncode represents an error that occurs during the encoding process.
-type ErrorEncode struct {
-	fieldName string
-	err       error
-}
-
-// Error returns the error message for ErrorEncode.
-func (e ErrorEncode) Error() string {
-	return fmt.Sprintf("unable to encode tag '%s': %s", e.fieldName, e.err)
-}
-
 // Marshal serializes the provided struct into a map containing form values.
 // `src` is the struct to be serialized, and the resulting map is returned.
 //
@@ -36,9 +29,9 @@ func (e ErrorEncode) Error() string {
 //	var data SampleForm
 //	formData, err := form.Marshal(data)
 //	if err != nil { ...	}
-func Marshal(src any) (map[string][]string, error) {
+func Marshal(src any, opts ...Option) (map[string][]string, error) {
 	dest := map[string][]string{}
-	err := NewEncoder(dest).Encode(src)
+	err := NewEncoder(dest, opts...).Encode(src)
 	if err != nil {
 		return nil, err
 	}
@@ -46,14 +39,89 @@ func Marshal(src any) (map[string][]string, error) {
 	return dest, nil
 }
 
+// EncodeToString serializes the provided struct into an `application/x-www-form-urlencoded` string. Values follow the
+// order of MarshalPairs and are percent-encoded, so the same struct always produces the same string.
+//
+// Example:
+//
+//	body, err := form.EncodeToString(data)
+//	if err != nil { ... }
+//	// body == "name=Tavish+DeGroot&age=30"
+func EncodeToString(src any, opts ...Option) (string, error) {
+	pairs, err := MarshalPairs(src, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return pairs.Encode(), nil
+}
+
+// EncodeQuery serializes the provided struct into the URL's query. Encoded keys replace any values the query already
+// holds for them, and every other key in the query is kept, sorted, before the encoded values.
+//
+// Example:
+//
+//	u, _ := url.Parse("https://example.com/search?page=2")
+//	err := form.EncodeQuery(u, filters)
+//	if err != nil { ... }
+func EncodeQuery(u *url.URL, src any, opts ...Option) error {
+	pairs, err := MarshalPairs(src, opts...)
+	if err != nil {
+		return err
+	}
+
+	query := u.Query()
+	for key := range pairs.keys() {
+		delete(query, key)
+	}
+
+	u.RawQuery = query.Encode()
+	if len(query) > 0 && len(pairs) > 0 {
+		u.RawQuery += "&"
+	}
+	u.RawQuery += pairs.Encode()
+
+	return nil
+}
+
 // Encoder is responsible for encoding struct data into form values.
 type Encoder struct {
 	dest map[string][]string
+	opts options
+
+	// layout is the time layout of the field being encoded.
+	layout string
+
+	// part holds the file part options of the field being encoded.
+	part partOptions
+
+	// pairs collects the encoded values in order, when encoding with EncodePairs.
+	pairs *Pairs
+
+	// files collects the file parts, when encoding a multipart form.
+	files *[]filePart
 }
 
 // NewEncoder creates a new Encoder instance with the given destination map.
-func NewEncoder(dest map[string][]string) *Encoder {
-	return &Encoder{dest: dest}
+func NewEncoder(dest map[string][]string, opts ...Option) *Encoder {
+	return &Encoder{dest: dest, opts: newOptions(opts)}
+}
+
+// RegisterMarshaler registers a function that encodes values of the type as form values, for types that cannot
+// implement encoding.TextMarshaler, such as types from other packages. The marshaler takes precedence over every other
+// conversion, and is used for struct fields, slice elements, map keys, and map values of the type or of pointers to it.
+// Registered types are encoded as single values, even if they are structs.
+//
+// Marshalers must be registered before the Encoder is used.
+//
+// Example:
+//
+//	encoder := form.NewEncoder(formData)
+//	encoder.RegisterMarshaler(reflect.TypeOf(decimal.Decimal{}), func(val reflect.Value) (string, error) {
+//		return val.Interface().(decimal.Decimal).String(), nil
+//	})
+func (e *Encoder) RegisterMarshaler(t reflect.Type, marshal func(reflect.Value) (string, error)) {
+	e.opts.registry = e.opts.registry.withMarshaler(t, marshal)
 }
 
 // Encode serializes the provided struct into the destination map.
@@ -66,32 +134,27 @@ func (e *Encoder) Encode(src any) error {
 	}
 
 	if val.Kind() != reflect.Struct {
-		return fmt.Errorf("source (%v) must be a struct", src)
+		return fmt.Errorf("%w: source (%v) must be a struct", ErrInvalidSource, src)
 	}
 
-	return e.encodeStruct(val)
+	return e.encodeStruct(val, "")
 }
 
-// encodeStruct iterates over the fields of the provided struct and encodes them into form values.
-func (e *Encoder) encodeStruct(src reflect.Value) error {
-	// Iterate over the fields in src
+// encodeStruct iterates over the fields of the provided struct and encodes them into form values. Field keys are
+// scoped under `prefix`, which is empty for the top-level struct.
+func (e *Encoder) encodeStruct(src reflect.Value, prefix string) error {
+	// Iterate over the cached fields in src
 	srcType := src.Type()
-	for i := 0; i < src.NumField(); i++ {
-		fieldType := srcType.Field(i)
-		if !fieldType.IsExported() {
-			continue
-		}
-
-		formTag, shouldOmitEmpty := parseFieldTag(fieldType)
-		if formTag != "" && formTag != "-" {
-			// Parse based on field type. All field types but `map` look up their values from src. `map` must iterate
-			// over src keys to find all relevant key/value pairs.
-			fieldVal := src.Field(i)
-
-			err := e.encodeFormField(fieldVal, formTag, shouldOmitEmpty)
-			if err != nil {
-				return err
-			}
+	for _, field := range cachedStructInfo(srcType, &e.opts).fields {
+		fieldVal := src.Field(field.index)
+		fieldTag := e.opts.pathSyntax.joinField(prefix, field.tag)
+
+		layout, part := e.layout, e.part
+		e.layout, e.part = field.layout, field.part
+		err := field.encode(e, fieldVal, fieldTag, field.omitEmpty)
+		e.layout, e.part = layout, part
+		if err != nil {
+			return asEncodeError(err, fieldTag).withField(srcType, field.name)
 		}
 	}
 
@@ -100,52 +163,52 @@ func (e *Encoder) encodeStruct(src reflect.Value) error {
 
 // encodeFormField encodes the form value from the provided struct field based on the form tag.
 func (e *Encoder) encodeFormField(src reflect.Value, formTag string, shouldOmitEmpty bool) error {
-	if src.Type().Implements(textMarshalerType) ||
-		(src.CanAddr() && src.Addr().Type().Implements(textMarshalerType)) {
-		// If the destination itself doesn't implement TextMarshaler, take the pointer and recursively call
-		// encodeFormField.
-		if !src.Type().Implements(textMarshalerType) {
-			return e.encodeFormField(src.Addr(), formTag, shouldOmitEmpty)
-		}
-
-		// Ignore nil pointers
-		if src.Kind() == reflect.Pointer && src.IsNil() {
-			return nil
-		}
+	if e.files != nil && isFilePart(src.Type(), e.opts.registry) {
+		return e.encodeFile(src, formTag, shouldOmitEmpty)
+	}
 
-		// Don't include zero values with omitempty flags
-		if shouldOmitEmpty && isZeroValue(src) {
-			return nil
-		}
+	// Readers, and byte slices with file part options, can only be written as file parts of a multipart form
+	if src.Type() == readerType || (src.Type() == bytesType && e.part != partOptions{}) {
+		return nil
+	}
 
-		f := src.MethodByName("MarshalText")
-		ret := f.Call(nil)
-		if !ret[1].IsNil() {
-			return ErrorEncode{fieldName: formTag, err: ret[0].Interface().(error)}
-		}
+	kind := e.opts.registry.kindOf(src.Type())
+	if kind == kindScalar {
+		return e.encodeScalarField(src, formTag, shouldOmitEmpty)
+	}
 
-		// Convert returned bytes to string
-		retVal := ret[0]
-		retStr := string(retVal.Interface().([]byte))
+	// Uploaded files cannot be encoded as form values
+	if kind == kindFile {
+		return nil
+	}
 
-		e.dest[formTag] = append(e.dest[formTag], retStr)
+	// Encode the element the pointer references. Nil pointers are left out of the form.
+	for src.Kind() == reflect.Pointer {
+		if src.IsNil() {
+			return nil
+		}
 
-		return nil
+		src = src.Elem()
 	}
 
 	// Check for structured types
-	switch src.Kind() {
-	case reflect.Slice:
+	switch kind {
+	case kindSlice, kindIndexedSlice:
 		return e.encodeSliceField(src, formTag, shouldOmitEmpty)
 
-	case reflect.Map:
+	case kindMap:
 		return e.encodeMap(src, formTag, shouldOmitEmpty)
 
-	case reflect.Struct:
-		return e.encodeStruct(src)
-
 	default:
-		break
+		return e.encodeStruct(src, formTag)
+	}
+}
+
+// encodeScalarField encodes a single form value from the provided field.
+func (e *Encoder) encodeScalarField(src reflect.Value, formTag string, shouldOmitEmpty bool) error {
+	// Don't include zero values with omitempty flags
+	if shouldOmitEmpty && isZeroValue(src) {
+		return nil
 	}
 
 	encodedVal, err := e.encodeValue(src, formTag, shouldOmitEmpty)
@@ -156,18 +219,56 @@ func (e *Encoder) encodeFormField(src reflect.Value, formTag string, shouldOmitE
 		return nil
 	}
 
-	// Don't include zero values with omitempty flags
-	if shouldOmitEmpty && isZeroValue(src) {
-		return nil
-	}
-
 	e.dest[formTag] = append(e.dest[formTag], *encodedVal)
+	e.addPairs(formTag, *encodedVal)
 
 	return nil
 }
 
 // encodeValue encodes a single value from the struct into the destination form map.
 func (e *Encoder) encodeValue(src reflect.Value, formTag string, shouldOmitEmpty bool) (*string, error) {
+	// Registered marshalers take precedence over every other conversion
+	if e.opts.registry.isRegistered(src.Type()) {
+		return e.marshalValue(src, formTag)
+	}
+
+	// Time layouts from the field's tag take precedence over time.Time's text format
+	if e.layout != "" && (src.Type() == timeType || src.Type() == timePtrType) {
+		if src.Kind() == reflect.Pointer {
+			if src.IsNil() {
+				return nil, nil
+			}
+
+			src = src.Elem()
+		}
+
+		t := src.Interface().(time.Time)
+		if e.opts.loc != nil {
+			t = t.In(e.opts.loc)
+		}
+
+		return toPtr(formatTime(e.layout, t)), nil
+	}
+
+	// Check overridden TextMarshaler types first. If only the pointer implements TextMarshaler, encode through the
+	// pointer.
+	if isTextMarshaler(src) {
+		if !src.Type().Implements(textMarshalerType) {
+			return e.encodeValue(src.Addr(), formTag, shouldOmitEmpty)
+		}
+
+		if src.Kind() == reflect.Pointer && src.IsNil() {
+			return nil, nil
+		}
+
+		text, err := src.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, ErrorEncode{Path: formTag, Value: src.Interface(), Err: err}
+		}
+
+		return toPtr(string(text)), nil
+	}
+
 	switch src.Type() {
 	case durationType:
 		return toPtr(fmt.Sprintf("%s", src)), nil
@@ -200,22 +301,75 @@ func (e *Encoder) encodeValue(src reflect.Value, formTag string, shouldOmitEmpty
 		return e.encodeValue(src.Elem(), formTag, shouldOmitEmpty)
 
 	default:
-		return nil, ErrorEncode{fieldName: formTag, err: fmt.Errorf("unsupported kind %v", src.Kind())}
+		return nil, ErrorEncode{Path: formTag, Value: src.Interface(), Err: fmt.Errorf("%w %v", ErrUnsupportedType, src.Type())}
 	}
 }
 
+// marshalValue encodes a value with the marshaler registered for its type. Pointers are dereferenced until they
+// reference a registered type, and nil pointers are left out of the form.
+func (e *Encoder) marshalValue(src reflect.Value, formTag string) (*string, error) {
+	marshal, ok := e.opts.registry.marshalers[src.Type()]
+	if !ok {
+		if src.Kind() != reflect.Pointer {
+			return nil, ErrorEncode{Path: formTag, Value: src.Interface(), Err: fmt.Errorf("%w %v", ErrUnsupportedType, src.Type())}
+		}
+		if src.IsNil() {
+			return nil, nil
+		}
+
+		return e.marshalValue(src.Elem(), formTag)
+	}
+
+	text, err := marshal(src)
+	if err != nil {
+		return nil, ErrorEncode{Path: formTag, Value: src.Interface(), Err: err}
+	}
+
+	return &text, nil
+}
+
 // encodeSliceField encodes the form values from the provided slice field.
 func (e *Encoder) encodeSliceField(src reflect.Value, formTag string, shouldOmitEmpty bool) error {
 	if src.Len() == 0 && shouldOmitEmpty {
 		return nil
 	}
 
+	if e.opts.registry.kindOf(src.Type()) == kindIndexedSlice {
+		return e.encodeIndexedSlice(src, formTag)
+	}
+
 	values, err := e.encodeSliceValue(src, formTag, shouldOmitEmpty)
 	if err != nil {
 		return err
 	}
 
 	e.dest[formTag] = values
+	e.addPairs(formTag, values...)
+
+	return nil
+}
+
+// addPairs records the encoded values of the form key, when encoding with EncodePairs.
+func (e *Encoder) addPairs(formTag string, values ...string) {
+	if e.pairs == nil {
+		return
+	}
+
+	for _, value := range values {
+		*e.pairs = append(*e.pairs, Pair{Key: formTag, Value: value})
+	}
+}
+
+// encodeIndexedSlice encodes each element of a slice of structured values under an indexed form key:
+// `items[0][name]`, `items[1][name]`.
+func (e *Encoder) encodeIndexedSlice(src reflect.Value, formTag string) error {
+	for i := 0; i < src.Len(); i++ {
+		elemTag := joinIndex(formTag, strconv.Itoa(i))
+		err := e.encodeFormField(src.Index(i), elemTag, false)
+		if err != nil {
+			return asEncodeError(err, elemTag).withIndex(strconv.Itoa(i))
+		}
+	}
 
 	return nil
 }
@@ -230,7 +384,10 @@ func (e *Encoder) encodeSliceValue(src reflect.Value, formTag string, shouldOmit
 	for i := 0; i < src.Len(); i++ {
 		encodedVal, err := e.encodeValue(src.Index(i), formTag, shouldOmitEmpty)
 		if err != nil {
-			return nil, ErrorEncode{fieldName: formTag, err: fmt.Errorf("unable to encode slice %s: %w", formTag, err)}
+			return nil, asEncodeError(err, formTag).withIndex(strconv.Itoa(i))
+		}
+		if encodedVal == nil {
+			continue
 		}
 
 		values = append(values, *encodedVal)
@@ -245,52 +402,204 @@ func (e *Encoder) encodeMap(src reflect.Value, formTag string, shouldOmitEmpty b
 		return nil
 	}
 
+	type mapEntry struct {
+		key    string
+		keyVal reflect.Value
+		val    reflect.Value
+	}
+
+	entries := make([]mapEntry, 0, src.Len())
 	for _, key := range src.MapKeys() {
-		mapKey := fmt.Sprintf("%s[%s]", formTag, key)
-		val := src.MapIndex(key)
-
-		// Handle single values or slices
-		if val.Kind() == reflect.Slice {
-			encodedVal, err := e.encodeSliceValue(val, formTag, shouldOmitEmpty)
-			if err != nil {
-				return ErrorEncode{fieldName: formTag, err: fmt.Errorf("unable to encode map key %s: %w", mapKey, err)}
-			}
+		// Copy the map key and value so that pointer receiver TextMarshaler implementations can be addressed
+		keyVal := reflect.New(src.Type().Key()).Elem()
+		keyVal.Set(key)
+		val := reflect.New(src.Type().Elem()).Elem()
+		val.Set(src.MapIndex(key))
+
+		// Map keys are formatted like scalar values
+		encodedKey, err := e.encodeValue(keyVal, formTag, false)
+		if err != nil {
+			return asEncodeError(err, formTag).withIndex(fmt.Sprint(key.Interface()))
+		}
+		if encodedKey == nil {
+			continue
+		}
+		if strings.ContainsAny(*encodedKey, "[]") {
+			err = fmt.Errorf("%w %q: brackets cannot be decoded from form keys", ErrInvalidMapKey, *encodedKey)
+			return ErrorEncode{Path: formTag, Value: key.Interface(), Index: *encodedKey, Err: err}
+		}
 
-			e.dest[mapKey] = encodedVal
-		} else {
-			encodedVal, err := e.encodeValue(val, formTag, shouldOmitEmpty)
-			if err != nil {
-				return ErrorEncode{fieldName: formTag, err: fmt.Errorf("unable encode map key %s: %w", mapKey, err)}
-			}
+		entries = append(entries, mapEntry{key: *encodedKey, keyVal: keyVal, val: val})
+	}
 
-			e.dest[mapKey] = append(e.dest[mapKey], *encodedVal)
+	// Encode entries in sorted key order, so the output does not depend on map iteration order. Numeric keys are sorted
+	// by value, so `items[9]` comes before `items[10]`.
+	slices.SortFunc(entries, func(a, b mapEntry) int {
+		if order := compareNumbers(a.keyVal, b.keyVal); order != 0 {
+			return order
+		}
+		return strings.Compare(a.key, b.key)
+	})
+
+	for _, entry := range entries {
+		mapKey := joinIndex(formTag, entry.key)
+		err := e.encodeFormField(entry.val, mapKey, false)
+		if err != nil {
+			return asEncodeError(err, mapKey).withIndex(entry.key)
 		}
 	}
 
 	return nil
 }
 
-// parseFieldTag parses the field's "form" tag.
-// Returns the provided tag value and an omitempty flag, if omitempty is present
-func parseFieldTag(fieldType reflect.StructField) (string, bool) {
-	formTag := fieldType.Tag.Get("form")
-	if formTag == "" {
-		return "", false
-	}
+// compareNumbers compares two integer, unsigned, or float values of the same type. Other values compare as equal.
+func compareNumbers(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
 
-	tagParts := strings.Split(formTag, ",")
-	if len(tagParts) == 1 {
-		return tagParts[0], false
+	default:
+		return 0
 	}
+}
+
+// tagOptions holds the options parsed from a field's tag.
+type tagOptions struct {
+	omitEmpty bool
+	required  bool
+
+	// duplicates is the field's DuplicatePolicy, or zero to use the Decoder's policy.
+	duplicates DuplicatePolicy
+
+	// defaults holds the form values decoded when the field's form key is absent, or nil if the field has no default.
+	defaults []string
+
+	// layout is the named preset or custom layout for time.Time values, or empty to use time.Time's text format.
+	layout string
+
+	// uploads holds the restrictions on the field's uploaded files.
+	uploads uploadLimits
+
+	// part holds the file part options for encoding the field as a multipart form.
+	part partOptions
+}
+
+// parseFieldTag parses the field's tag, checking each configured tag name in order. The first tag that is present
+// provides the field's form key and options. If the tag has no name, or none of the tags are present, the form key is
+// derived from the field name using the configured naming strategy.
+// Returns the form key and the tag options. Unrecognized options are ignored, so that tags shared with other libraries
+// can be used.
+func parseFieldTag(fieldType reflect.StructField, o *options) (string, tagOptions, error) {
+	for _, tagName := range o.tagNames {
+		formTag, ok := fieldType.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+
+		tagParts := strings.Split(formTag, ",")
+		tag := tagParts[0]
+		if tag == "" {
+			tag = o.naming.name(fieldType.Name)
+		}
+
+		var (
+			opts tagOptions
+			last string
+		)
+		for _, part := range tagParts[1:] {
+			option, value, _ := strings.Cut(part, "=")
+			previous := last
+			last = option
+
+			switch option {
+			case "omitempty":
+				opts.omitEmpty = true
+
+			case "required":
+				opts.required = true
+
+			case "dup":
+				// Slices receive every value, so there are no duplicates to resolve
+				kind := o.registry.kindOf(fieldType.Type)
+				if kind == kindSlice || kind == kindIndexedSlice {
+					return tag, opts, fmt.Errorf("%w: dup is not supported for slice fields", ErrInvalidTag)
+				}
+
+				policy, err := parseDuplicatePolicy(value)
+				if err != nil {
+					return tag, opts, err
+				}
+				opts.duplicates = policy
+
+			case "layout":
+				layout, err := parseTimeLayout(fieldType.Type, value)
+				if err != nil {
+					return tag, opts, err
+				}
+				opts.layout = layout
+
+			case "maxsize", "accept":
+				if !isFileField(fieldType.Type, o.registry) {
+					return tag, opts, fmt.Errorf("%w: %s is only supported for file fields", ErrInvalidTag, option)
+				}
+
+				if option == "accept" {
+					opts.uploads.accept = strings.Split(value, "|")
+					continue
+				}
+
+				size, err := parseSize(value)
+				if err != nil {
+					return tag, opts, err
+				}
+				opts.uploads.maxSize = size
+
+			case "filename", "contenttype":
+				if !isFilePart(fieldType.Type, o.registry) {
+					return tag, opts, fmt.Errorf("%w: %s is only supported for file fields", ErrInvalidTag, option)
+				}
+
+				if option == "filename" {
+					opts.part.filename = value
+					continue
+				}
+				opts.part.contentType = value
+
+			case "default":
+				// Slice defaults list each value: `default=a|b`
+				opts.defaults = []string{value}
+				if o.registry.kindOf(fieldType.Type) == kindSlice {
+					opts.defaults = strings.Split(value, "|")
+				}
+
+			default:
+				// Options are separated by commas, so a comma in a value splits it into an unknown option
+				if previous == "default" || previous == "layout" {
+					return tag, opts, fmt.Errorf("%w: %s values cannot contain commas", ErrInvalidTag, previous)
+				}
+			}
+		}
 
-	tag := tagParts[0]
-	for _, part := range tagParts {
-		if part == "omitempty" {
-			return tag, true
+		if opts.required && opts.defaults != nil {
+			return tag, opts, fmt.Errorf("%w: required fields cannot have a default value", ErrInvalidTag)
 		}
+
+		return tag, opts, nil
 	}
 
-	return tag, false
+	return o.naming.name(fieldType.Name), tagOptions{}, nil
+}
+
+// isTextMarshaler reports whether the value, or a pointer to the value, implements encoding.TextMarshaler.
+func isTextMarshaler(val reflect.Value) bool {
+	return val.Type().Implements(textMarshalerType) ||
+		(val.CanAddr() && val.Addr().Type().Implements(textMarshalerType))
 }
 
 // isZeroValue checks if the provided value is the zero value for its type.
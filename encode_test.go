@@ -6,7 +6,10 @@ import (
 	"github.com/gorilla/schema"
 	"github.com/stretchr/testify/assert"
 	"math"
+	"net/netip"
 	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -108,6 +111,39 @@ func TestMarshal(t *testing.T) {
 				},
 			},
 		},
+		{
+			in: In{
+				name: "success -- nested struct",
+				FormData: FormStruct{
+					NestedStruct: FormStructNested{
+						NestedString: "nested",
+						NestedInt:    3,
+					},
+				},
+			},
+			out: Out{
+				Resp: url.Values{
+					"nestedStruct[nestedString]": []string{"nested"},
+					"nestedStruct[nestedInt]":    []string{"3"},
+				},
+			},
+		},
+		{
+			in: In{
+				name: "success -- nested struct pointer",
+				FormData: CheckoutForm{
+					Billing:  Address{City: "Ullapool"},
+					Shipping: &Address{Street: "2 Side St", City: "Inverness"},
+				},
+			},
+			out: Out{
+				Resp: url.Values{
+					"billing[city]":    []string{"Ullapool"},
+					"shipping[street]": []string{"2 Side St"},
+					"shipping[city]":   []string{"Inverness"},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -123,6 +159,338 @@ func TestMarshal(t *testing.T) {
 	}
 }
 
+func TestMarshal_NestedPathSyntax(t *testing.T) {
+	checkout := CheckoutForm{
+		Billing:  Address{Street: "1 Main St", City: "Ullapool"},
+		Shipping: &Address{City: "Inverness"},
+	}
+
+	formValues, err := Marshal(checkout, WithPathSyntax(PathDot))
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string][]string{
+		"billing.street": {"1 Main St"},
+		"billing.city":   {"Ullapool"},
+		"shipping.city":  {"Inverness"},
+	}, formValues, "expected equal form values")
+
+	var roundTrip CheckoutForm
+	err = Unmarshal(formValues, &roundTrip, WithPathSyntax(PathDot))
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, checkout, roundTrip, "expected equal form struct")
+}
+
+func TestMarshal_IndexedSlice(t *testing.T) {
+	order := OrderForm{
+		Items:    []LineItem{{SKU: "A-1", Qty: 2}, {SKU: "B-2", Qty: 5}},
+		ItemPtrs: []*LineItem{{SKU: "C-3"}},
+	}
+
+	formValues, err := Marshal(order)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string][]string{
+		"items[0][sku]":    {"A-1"},
+		"items[0][qty]":    {"2"},
+		"items[1][sku]":    {"B-2"},
+		"items[1][qty]":    {"5"},
+		"itemPtrs[0][sku]": {"C-3"},
+	}, formValues, "expected equal form values")
+
+	var roundTrip OrderForm
+	err = Unmarshal(formValues, &roundTrip)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, order, roundTrip, "expected equal form struct")
+}
+
+func TestMarshal_NestedMap(t *testing.T) {
+	nested := NestedMapForm{
+		Addresses: map[string]Address{
+			"home":            {City: "Ullapool"},
+			"st. ann's & co.": {City: "Dundee"},
+		},
+		Matrix: map[string]map[string]int{
+			"a": {"b": 1, "c": 0},
+		},
+		Items: map[string][]LineItem{
+			"open": {{SKU: "A-1"}, {SKU: "B-2"}},
+		},
+		Deep: map[string]map[string][]Address{
+			"x": {"y": {{City: "Inverness"}}},
+		},
+	}
+
+	formValues, err := Marshal(nested)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string][]string{
+		"addresses[home][city]":            {"Ullapool"},
+		"addresses[st. ann's & co.][city]": {"Dundee"},
+		"matrix[a][b]":                     {"1"},
+		"matrix[a][c]":                     {"0"},
+		"items[open][0][sku]":              {"A-1"},
+		"items[open][1][sku]":              {"B-2"},
+		"deep[x][y][0][city]":              {"Inverness"},
+	}, formValues, "expected equal form values")
+
+	var roundTrip NestedMapForm
+	err = Unmarshal(formValues, &roundTrip)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, nested, roundTrip, "expected equal form struct")
+
+	// Keys with brackets would decode as nested keys, so they are rejected rather than silently lost
+	_, err = Marshal(NestedMapForm{Matrix: map[string]map[string]int{"a]b": {"c": 1}}})
+	assert.ErrorIs(t, err, ErrInvalidMapKey, "expected ErrInvalidMapKey")
+
+	var encodeErr ErrorEncode
+	assert.True(t, errors.As(err, &encodeErr), "expected ErrorEncode")
+	assert.Equal(t, "matrix", encodeErr.Path, "expected map path")
+	assert.Equal(t, "a]b", encodeErr.Index, "expected map key")
+}
+
+func TestMarshal_TypedMapKeys(t *testing.T) {
+	typed := TypedKeyForm{
+		IntKeys:  map[int]string{1: "one", -2: "minus two"},
+		UintKeys: map[uint8][]int{3: {4, 5}},
+		BoolKeys: map[bool]string{false: "no"},
+		TextKeys: map[netip.Addr]string{netip.MustParseAddr("::1"): "localhost"},
+	}
+
+	formValues, err := Marshal(typed)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string][]string{
+		"intKeys[1]":      {"one"},
+		"intKeys[-2]":     {"minus two"},
+		"uintKeys[3]":     {"4", "5"},
+		"boolKeys[false]": {"no"},
+		"textKeys[::1]":   {"localhost"},
+	}, formValues, "expected equal form values")
+
+	var roundTrip TypedKeyForm
+	err = Unmarshal(formValues, &roundTrip)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, typed, roundTrip, "expected equal form struct")
+
+	// Numeric keys are encoded in order of their values, rather than their formatted strings
+	pairs, err := MarshalPairs(TypedKeyForm{
+		IntKeys:  map[int]string{10: "ten", 9: "nine", -2: "minus two", 100: "hundred"},
+		UintKeys: map[uint8][]int{20: {1}, 3: {2}},
+	})
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, Pairs{
+		{Key: "intKeys[-2]", Value: "minus two"},
+		{Key: "intKeys[9]", Value: "nine"},
+		{Key: "intKeys[10]", Value: "ten"},
+		{Key: "intKeys[100]", Value: "hundred"},
+		{Key: "uintKeys[3]", Value: "2"},
+		{Key: "uintKeys[20]", Value: "1"},
+	}, pairs, "expected numeric key order")
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalText() ([]byte, error) {
+	return nil, errors.New("cannot marshal")
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	type UnsupportedForm struct {
+		Channel chan int `form:"channel"`
+	}
+	type ErrorForm struct {
+		Failing  *failingMarshaler           `form:"failing"`
+		Items    []failingMarshaler          `form:"items"`
+		Labelled map[string]failingMarshaler `form:"labelled"`
+	}
+
+	tests := []struct {
+		name     string
+		src      any
+		expected ErrorEncode
+		sentinel error
+	}{
+		{
+			name:     "unsupported type",
+			src:      UnsupportedForm{Channel: make(chan int)},
+			expected: ErrorEncode{Path: "channel", Field: "Channel", Struct: reflect.TypeOf(UnsupportedForm{})},
+			sentinel: ErrUnsupportedType,
+		},
+		{
+			name:     "failing marshaler",
+			src:      ErrorForm{Failing: &failingMarshaler{}},
+			expected: ErrorEncode{Path: "failing", Field: "Failing", Struct: reflect.TypeOf(ErrorForm{})},
+		},
+		{
+			name:     "failing slice element",
+			src:      ErrorForm{Items: []failingMarshaler{{}}},
+			expected: ErrorEncode{Path: "items", Field: "Items", Struct: reflect.TypeOf(ErrorForm{}), Index: "0"},
+		},
+		{
+			name:     "failing map value",
+			src:      ErrorForm{Labelled: map[string]failingMarshaler{"a": {}}},
+			expected: ErrorEncode{Path: "labelled[a]", Field: "Labelled", Struct: reflect.TypeOf(ErrorForm{}), Index: "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Marshal(tt.src)
+
+			var encodeErr ErrorEncode
+			assert.True(t, errors.As(err, &encodeErr), "expected ErrorEncode")
+			assert.Equal(t, tt.expected.Path, encodeErr.Path, "expected equal paths")
+			assert.Equal(t, tt.expected.Field, encodeErr.Field, "expected equal fields")
+			assert.Equal(t, tt.expected.Struct, encodeErr.Struct, "expected equal struct types")
+			assert.Equal(t, tt.expected.Index, encodeErr.Index, "expected equal indexes")
+			if tt.sentinel != nil {
+				assert.ErrorIs(t, err, tt.sentinel, "expected sentinel error")
+			}
+		})
+	}
+
+	_, err := Marshal([]string{})
+	assert.ErrorIs(t, err, ErrInvalidSource, "expected sentinel error")
+}
+
+func TestMarshal_TagNames(t *testing.T) {
+	tagged := TaggedForm{
+		Name:      "Tavish",
+		Nickname:  "demo",
+		UserID:    4,
+		Ignored:   "ignored",
+		HomePlace: &Address{City: "Ullapool"},
+		CreatedBy: "admin",
+	}
+
+	formValues, err := Marshal(tagged, WithTagNames("schema", "json", "form"), WithNaming(NamingKebabCase))
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string][]string{
+		"name":       {"Tavish"},
+		"nickname":   {"demo"},
+		"id":         {"4"},
+		"home[city]": {"Ullapool"},
+		"created-by": {"admin"},
+	}, formValues, "expected equal form values")
+}
+
+func TestEncoder_RegisterMarshaler(t *testing.T) {
+	invoice := InvoiceForm{
+		Total:    Money{Cents: 1234},
+		Discount: &Money{Cents: 50},
+		Lines:    []Money{{Cents: 100}, {Cents: 250}},
+		Refunds:  []*Money{{Cents: 25}, nil},
+		Budgets:  map[string]Money{"ops": {Cents: 10000}},
+		Tiers:    map[Money]string{{Cents: 500}: "gold"},
+		Fallback: Money{Cents: 100},
+	}
+
+	formValues := map[string][]string{}
+	encoder := NewEncoder(formValues)
+	encoder.RegisterMarshaler(reflect.TypeOf(Money{}), func(val reflect.Value) (string, error) {
+		cents := val.Interface().(Money).Cents
+		return fmt.Sprintf("%d.%02d", cents/100, cents%100), nil
+	})
+
+	err := encoder.Encode(invoice)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string][]string{
+		"total":        {"12.34"},
+		"discount":     {"0.50"},
+		"lines":        {"1.00", "2.50"},
+		"refunds":      {"0.25"},
+		"budgets[ops]": {"100.00"},
+		"tiers[5.00]":  {"gold"},
+		"fallback":     {"1.00"},
+	}, formValues, "expected marshaled form values")
+
+	decoder := NewDecoder()
+	decoder.RegisterConverter(reflect.TypeOf(Money{}), parseMoney)
+
+	var roundTrip InvoiceForm
+	err = decoder.Decode(formValues, &roundTrip)
+	assert.NoError(t, err, "unexpected error")
+	invoice.Refunds = invoice.Refunds[:1]
+	assert.Equal(t, invoice, roundTrip, "expected equal form struct")
+
+	failing := NewEncoder(map[string][]string{})
+	failing.RegisterMarshaler(reflect.TypeOf(Money{}), func(val reflect.Value) (string, error) {
+		return "", errors.New("cannot marshal")
+	})
+	err = failing.Encode(InvoiceForm{Lines: []Money{{}}})
+
+	var encodeErr ErrorEncode
+	assert.True(t, errors.As(err, &encodeErr), "expected ErrorEncode")
+	assert.Equal(t, "total", encodeErr.Path, "expected the first failing field")
+	assert.EqualError(t, err, "unable to encode tag 'total': cannot marshal")
+}
+
+func TestEncodeToString(t *testing.T) {
+	contact := ContactRequest{Name: "Tavish DeGroot", Topic: "sales & support", Tags: []string{"b", "a"}}
+
+	body, err := EncodeToString(contact)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, "name=Tavish+DeGroot&topic=sales+%26+support&tags=b&tags=a&message=", body, "expected ordered, encoded body")
+
+	_, err = EncodeToString("not a struct")
+	assert.ErrorIs(t, err, ErrInvalidSource, "expected ErrInvalidSource")
+}
+
+func TestEncodeQuery(t *testing.T) {
+	u, err := url.Parse("https://example.com/contact?page=2&topic=billing")
+	assert.NoError(t, err, "unexpected error")
+
+	err = EncodeQuery(u, struct {
+		Topic string   `form:"topic"`
+		Tags  []string `form:"tags"`
+	}{Topic: "sales", Tags: []string{"new"}})
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, "https://example.com/contact?page=2&topic=sales&tags=new", u.String(), "expected merged query")
+}
+
+func TestMarshalPairs(t *testing.T) {
+	type Profile struct {
+		Name  string            `form:"name"`
+		Tags  []string          `form:"tags"`
+		Meta  map[string]string `form:"meta"`
+		Home  Address           `form:"home"`
+		Alias string            `form:"alias"`
+	}
+	profile := Profile{
+		Name:  "Tavish",
+		Tags:  []string{"b", "a"},
+		Meta:  map[string]string{"z": "1", "a": "2", "m": "3"},
+		Home:  Address{City: "Ullapool"},
+		Alias: "Demo",
+	}
+
+	pairs, err := MarshalPairs(profile)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, Pairs{
+		{Key: "name", Value: "Tavish"},
+		{Key: "tags", Value: "b"},
+		{Key: "tags", Value: "a"},
+		{Key: "meta[a]", Value: "2"},
+		{Key: "meta[m]", Value: "3"},
+		{Key: "meta[z]", Value: "1"},
+		{Key: "home[city]", Value: "Ullapool"},
+		{Key: "alias", Value: "Demo"},
+	}, pairs, "expected field order with sorted map keys")
+
+	formValues, err := Marshal(profile)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, formValues, pairs.Values(), "expected the same values as Marshal")
+
+	// Encoding is repeatable, regardless of map iteration order
+	for i := 0; i < 10; i++ {
+		body, err := EncodeToString(profile)
+		assert.NoError(t, err, "unexpected error")
+		assert.Equal(t, "name=Tavish&tags=b&tags=a&meta%5Ba%5D=2&meta%5Bm%5D=3&meta%5Bz%5D=1&home%5Bcity%5D=Ullapool&alias=Demo",
+			body, "expected deterministic body")
+	}
+
+	body, err := EncodeToString(profile, WithKeyOrder(strings.Compare))
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, "alias=Demo&home%5Bcity%5D=Ullapool&meta%5Ba%5D=2&meta%5Bm%5D=3&meta%5Bz%5D=1&name=Tavish&tags=b&tags=a",
+		body, "expected custom key order, keeping the order of values")
+}
+
 func BenchmarkEncode(b *testing.B) {
 	benchForm := BenchmarkForm{
 		ID:    123,
@@ -241,6 +609,25 @@ func BenchmarkGorillaSchemaEncodeLarge(b *testing.B) {
 	_ = fmt.Sprintf("%s", out)
 }
 
+func BenchmarkEncodeNested(b *testing.B) {
+	benchForm := BenchmarkFormNested{
+		Email:    "a@example.com",
+		Billing:  Address{Street: "1 Main St", City: "Ullapool"},
+		Shipping: &Address{Street: "2 Side St", City: "Inverness"},
+		Items:    []LineItem{{SKU: "A-1", Qty: 2}, {SKU: "B-2", Qty: 5}},
+		Meta:     map[string]string{"source": "web"},
+	}
+
+	out := map[string][]string{}
+	for i := 0; i < b.N; i++ {
+		err := NewEncoder(out).Encode(benchForm)
+		if err != nil {
+			b.Fatalf("Unmarshal failed: %v", err)
+		}
+	}
+	_ = fmt.Sprintf("%s", out)
+}
+
 // FuzzStruct does not include time.Time or time.Duration values, as they are unlikely to round-trip marshal and
 // unmarshal.
 type FuzzStruct struct {
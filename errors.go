@@ -0,0 +1,249 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidDestination is returned when the destination passed to Decode is not a non-nil pointer to a struct.
+	ErrInvalidDestination = errors.New("invalid destination")
+
+	// ErrInvalidSource is returned when the source passed to Encode is not a struct or a pointer to a struct.
+	ErrInvalidSource = errors.New("invalid source")
+
+	// ErrUnsupportedType is returned when a struct field has a type that cannot be decoded from or encoded to form
+	// values.
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	// ErrOverflow is matched by errors.Is when a form value is out of range for its numeric field type.
+	ErrOverflow = errors.New("value out of range")
+
+	// ErrUnknownKey is returned by a Decoder created with WithStrict for each form key that does not match a struct
+	// field.
+	ErrUnknownKey = errors.New("unknown form key")
+
+	// ErrDuplicateValue is returned when a field that holds a single value receives multiple form values, and the
+	// field's DuplicatePolicy is DuplicateError.
+	ErrDuplicateValue = errors.New("duplicate form value")
+
+	// ErrMissing is returned for a field with the `required` tag option whose form key is absent or only has empty
+	// values.
+	ErrMissing = errors.New("missing form value")
+
+	// ErrValidation is matched by errors.Is when a decoded value fails a rule from its field's `validate` tag.
+	ErrValidation = errors.New("validation failed")
+
+	// ErrBodyTooLarge is returned by DecodeRequest when the request body exceeds the maximum body size.
+	ErrBodyTooLarge = errors.New("request body too large")
+
+	// ErrMalformedRequest is returned by DecodeRequest when the URL query or request body cannot be parsed.
+	ErrMalformedRequest = errors.New("malformed form data")
+
+	// ErrUnsupportedContentType is returned by DecodeRequest when the request body is not form data.
+	ErrUnsupportedContentType = errors.New("unsupported content type")
+
+	// ErrFileTooLarge is returned when an uploaded file exceeds the size from its field's `maxsize` tag option.
+	ErrFileTooLarge = errors.New("file too large")
+
+	// ErrFileType is returned when an uploaded file's media type is not listed in its field's `accept` tag option.
+	ErrFileType = errors.New("file type not accepted")
+
+	// ErrLimitExceeded is matched by errors.Is when form input exceeds one of the limits set with WithMaxKeys,
+	// WithMaxKeySize, WithMaxValueSize, WithMaxDepth, WithMaxSliceLen, or WithMaxMapEntries.
+	ErrLimitExceeded = errors.New("limit exceeded")
+
+	// ErrInvalidMapKey is returned when encoding a map key that contains `[` or `]`, which could not be decoded from
+	// the form key.
+	ErrInvalidMapKey = errors.New("invalid map key")
+
+	// ErrInvalidTag is returned when a struct field's tag has an invalid option.
+	ErrInvalidTag = errors.New("invalid struct tag")
+)
+
+// ErrorDecode represents an error that occurs during the decoding process.
+type ErrorDecode struct {
+	// Path is the full form key of the value that failed to decode, such as `items[0][qty]`.
+	Path string
+
+	// Field is the name of the Go struct field that failed to decode.
+	Field string
+
+	// Struct is the type of the struct that holds Field. For errors returned by a FormValidator, Struct is the type
+	// that implements FormValidator, and Field is empty.
+	Struct reflect.Type
+
+	// Value is the raw form value that failed to decode, if any.
+	Value string
+
+	// Index is the slice index or map key that failed to decode, if the failure occurred inside a slice or map.
+	Index string
+
+	// Err is the underlying cause.
+	Err error
+}
+
+// Error returns the error message for ErrorDecode.
+func (e ErrorDecode) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("Unable to decode form: %s", e.Err)
+	}
+
+	return fmt.Sprintf("Unable to decode tag '%s': %s", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying cause, so that errors.Is and errors.As can inspect it.
+func (e ErrorDecode) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches ErrOverflow, which wraps strconv's range errors.
+func (e ErrorDecode) Is(target error) bool {
+	return target == ErrOverflow && errors.Is(e.Err, strconv.ErrRange)
+}
+
+// withField returns a copy of the error attributed to the named field of the struct type, unless a field is already
+// set.
+func (e ErrorDecode) withField(structType reflect.Type, name string) ErrorDecode {
+	if e.Field == "" {
+		e.Field = name
+		e.Struct = structType
+	}
+
+	return e
+}
+
+// DecodeErrors is the collection of errors returned by a Decoder created with WithAllErrors. It holds one ErrorDecode
+// for each form value that failed to decode. A Decoder created with WithStrict also returns DecodeErrors, holding one
+// ErrorDecode for each unknown form key.
+type DecodeErrors []ErrorDecode
+
+// Error returns the error messages for each ErrorDecode, separated by semicolons.
+func (e DecodeErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns each ErrorDecode in the collection, so that errors.Is and errors.As inspect every error.
+func (e DecodeErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// ErrorRule is the cause of an ErrorDecode for a value that fails a rule from its field's `validate` tag.
+type ErrorRule struct {
+	// Rule is the name of the rule that failed, such as `min` or `email`.
+	Rule string
+
+	// Param is the rule's parameter, such as `3` for `min=3`, or empty if the rule has none.
+	Param string
+}
+
+// Error returns the error message for ErrorRule.
+func (e ErrorRule) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("failed validation rule '%s'", e.Rule)
+	}
+
+	return fmt.Sprintf("failed validation rule '%s=%s'", e.Rule, e.Param)
+}
+
+// Is reports whether the target is ErrValidation.
+func (e ErrorRule) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ErrorLimit is the cause of an ErrorDecode for form input that exceeds one of the Decoder's limits.
+type ErrorLimit struct {
+	// Limit names the limit that was exceeded: `key count`, `key size`, `value size`, `depth`, `slice length`, or
+	// `map size`.
+	Limit string
+
+	// Max is the configured maximum.
+	Max int
+
+	// Path is the form key that exceeded the limit. It is empty for the key count, and for keys too large for a
+	// StreamDecoder to read.
+	Path string
+}
+
+// Error returns the error message for ErrorLimit.
+func (e ErrorLimit) Error() string {
+	return fmt.Sprintf("%s exceeds the limit of %d", e.Limit, e.Max)
+}
+
+// Is reports whether the target is ErrLimitExceeded.
+func (e ErrorLimit) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// ErrorEncode represents an error that occurs during the encoding process.
+type ErrorEncode struct {
+	// Path is the full form key of the value that failed to encode, such as `items[0][qty]`.
+	Path string
+
+	// Field is the name of the Go struct field that failed to encode.
+	Field string
+
+	// Struct is the type of the struct that holds Field.
+	Struct reflect.Type
+
+	// Value is the Go value that failed to encode.
+	Value any
+
+	// Index is the slice index or map key that failed to encode, if the failure occurred inside a slice or map.
+	Index string
+
+	// Err is the underlying cause.
+	Err error
+}
+
+// Error returns the error message for ErrorEncode.
+func (e ErrorEncode) Error() string {
+	return fmt.Sprintf("unable to encode tag '%s': %s", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying cause, so that errors.Is and errors.As can inspect it.
+func (e ErrorEncode) Unwrap() error {
+	return e.Err
+}
+
+// withField returns a copy of the error attributed to the named field of the struct type, unless a field is already
+// set.
+func (e ErrorEncode) withField(structType reflect.Type, name string) ErrorEncode {
+	if e.Field == "" {
+		e.Field = name
+		e.Struct = structType
+	}
+
+	return e
+}
+
+// withIndex returns a copy of the error attributed to the slice index or map key, unless an index is already set.
+func (e ErrorEncode) withIndex(index string) ErrorEncode {
+	if e.Index == "" {
+		e.Index = index
+	}
+
+	return e
+}
+
+// asEncodeError returns the error as an ErrorEncode for the given form key.
+func asEncodeError(err error, formTag string) ErrorEncode {
+	if encodeErr, ok := err.(ErrorEncode); ok {
+		return encodeErr
+	}
+
+	return ErrorEncode{Path: formTag, Err: err}
+}
@@ -0,0 +1,168 @@
+package form
+
+import (
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+
+// uploadLimits holds the restrictions on uploaded files from a field's `maxsize` and `accept` tag options.
+type uploadLimits struct {
+	// maxSize is the maximum size of each file in bytes, or zero for no limit.
+	maxSize int64
+
+	// accept holds the allowed media types, such as `image/png` or `image/*`, or nil to allow any type.
+	accept []string
+}
+
+// DecodeMultipart decodes the values and files of a multipart form into the provided destination struct. Fields of
+// type *multipart.FileHeader, []*multipart.FileHeader, and maps of them are populated from the form's files, and every
+// other field from its values. DecodeMultipart is safe for concurrent use.
+//
+// Example:
+//
+//	type Upload struct {
+//		Title    string                  `form:"title"`
+//		Document *multipart.FileHeader   `form:"document,maxsize=5MB,accept=application/pdf"`
+//		Images   []*multipart.FileHeader `form:"images,accept=image/*"`
+//	}
+//
+//	err := r.ParseMultipartForm(32 << 20)
+//	if err != nil { ... }
+//
+//	var upload Upload
+//	err = decoder.DecodeMultipart(r.MultipartForm, &upload)
+func (d *Decoder) DecodeMultipart(f *multipart.Form, dest any) error {
+	return d.decode(f.Value, f.File, dest)
+}
+
+// isFileField reports whether the field holds uploaded files, directly or as map values.
+func isFileField(t reflect.Type, r *registry) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+
+	return r.kindOf(t) == kindFile
+}
+
+// parseSize parses a file size from a `maxsize` tag option, in bytes or with a KB, MB, or GB suffix: `5MB`.
+func parseSize(size string) (int64, error) {
+	multiplier := int64(1)
+	for suffix, unit := range map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30} {
+		if strings.HasSuffix(size, suffix) {
+			size = strings.TrimSuffix(size, suffix)
+			multiplier = unit
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(size, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: invalid file size %q", ErrInvalidTag, size)
+	}
+
+	return n * multiplier, nil
+}
+
+// decodeFileField decodes the uploaded files under the form key into a file header, or a slice of file headers.
+func (d *decodeState) decodeFileField(dest reflect.Value, formTag string) error {
+	headers := d.files[formTag]
+	d.consume(formTag)
+
+	// Decode the slice a pointer references
+	for dest.Kind() == reflect.Pointer && dest.Type().Elem() != fileHeaderType {
+		ensurePointerIsSet(dest)
+		dest = dest.Elem()
+	}
+
+	if dest.Kind() != reflect.Slice {
+		header := headers[0]
+		if len(headers) > 1 {
+			switch d.duplicates {
+			case DuplicateError:
+				return ErrorDecode{Path: formTag, Err: fmt.Errorf("%w: received %d files", ErrDuplicateValue, len(headers))}
+			case DuplicateLast:
+				header = headers[len(headers)-1]
+			}
+		}
+
+		err := d.checkFile(header, formTag)
+		if err != nil {
+			return err
+		}
+
+		setFile(dest, header)
+		return nil
+	}
+
+	err := checkLen("slice length", d.opts.maxSliceLen, len(headers), formTag)
+	if err != nil {
+		return err
+	}
+
+	for i, header := range headers {
+		err := d.checkFile(header, formTag)
+		if err != nil {
+			err = d.collect(err, formTag, strconv.Itoa(i))
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		elem := reflect.New(dest.Type().Elem()).Elem()
+		setFile(elem, header)
+		dest.Set(reflect.Append(dest, elem))
+	}
+
+	return nil
+}
+
+// checkFile returns an error if the uploaded file exceeds the field's maximum size, or does not have an accepted
+// media type. The media type is the Content-Type declared by the client for the file.
+func (d *decodeState) checkFile(header *multipart.FileHeader, formTag string) error {
+	if d.uploads.maxSize > 0 && header.Size > d.uploads.maxSize {
+		return ErrorDecode{
+			Path:  formTag,
+			Value: header.Filename,
+			Err:   fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes", ErrFileTooLarge, header.Size, d.uploads.maxSize),
+		}
+	}
+
+	if d.uploads.accept == nil {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(header.Header.Get("Content-Type"))
+	for _, accepted := range d.uploads.accept {
+		if accepted == mediaType {
+			return nil
+		}
+
+		// Wildcards match any subtype: `image/*`
+		if prefix, ok := strings.CutSuffix(accepted, "*"); ok && mediaType != "" && strings.HasPrefix(mediaType, prefix) {
+			return nil
+		}
+	}
+
+	return ErrorDecode{Path: formTag, Value: header.Filename, Err: fmt.Errorf("%w %q", ErrFileType, mediaType)}
+}
+
+// setFile sets the destination, a file header or a pointer to one, to the uploaded file.
+func setFile(dest reflect.Value, header *multipart.FileHeader) {
+	if dest.Type() == fileHeaderType {
+		dest.Set(reflect.ValueOf(header).Elem())
+		return
+	}
+
+	dest.Set(reflect.ValueOf(header))
+}
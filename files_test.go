@@ -0,0 +1,143 @@
+package form
+
+import (
+	"errors"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type UploadForm struct {
+	Title       string                           `form:"title"`
+	Avatar      *multipart.FileHeader            `form:"avatar,maxsize=1KB,accept=image/png|image/jpeg"`
+	Photos      []*multipart.FileHeader          `form:"photos,accept=image/*"`
+	Attachments map[string]*multipart.FileHeader `form:"attachments,maxsize=8"`
+	Resume      multipart.FileHeader             `form:"resume"`
+}
+
+func TestDecodeRequest_Files(t *testing.T) {
+	r := newMultipartRequest(t, "/upload", map[string]string{"title": "Holiday"},
+		testFile{"avatar", "me.png", "image/png", "avatar"},
+		testFile{"photos", "beach.jpg", "image/jpeg", "beach"},
+		testFile{"photos", "sunset.webp", "image/webp", "sunset"},
+		testFile{"attachments[itinerary]", "plan.txt", "text/plain", "day one"},
+		testFile{"resume", "cv.pdf", "application/pdf", "resume"},
+	)
+
+	var dest UploadForm
+	err := DecodeRequest(r, &dest)
+	assert.NoError(t, err, "expected no error")
+
+	assert.Equal(t, "Holiday", dest.Title, "expected form value")
+	assert.Equal(t, "me.png", dest.Avatar.Filename, "expected single file")
+	assert.Equal(t, int64(6), dest.Avatar.Size, "expected file size")
+	assert.Len(t, dest.Photos, 2, "expected every file")
+	assert.Equal(t, "sunset.webp", dest.Photos[1].Filename, "expected files in order")
+	assert.Equal(t, "plan.txt", dest.Attachments["itinerary"].Filename, "expected file map")
+	assert.Equal(t, "cv.pdf", dest.Resume.Filename, "expected file header value")
+
+	file, err := dest.Avatar.Open()
+	assert.NoError(t, err, "expected file to open")
+	defer file.Close()
+
+	// Form values only decode files from the request body
+	r = newMultipartRequest(t, "/upload", nil, testFile{"avatar", "me.png", "image/png", "avatar"})
+	err = DecodeRequest(r, &dest, WithSource(SourceQuery))
+	assert.NoError(t, err, "expected no error")
+}
+
+func TestDecodeRequest_FileErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []testFile
+		opts  []Option
+		err   error
+		path  string
+		value string
+	}{
+		{
+			name:  "too large",
+			files: []testFile{{"avatar", "me.png", "image/png", string(make([]byte, 1025))}},
+			err:   ErrFileTooLarge,
+			path:  "avatar",
+			value: "me.png",
+		},
+		{
+			name:  "type not accepted",
+			files: []testFile{{"avatar", "me.gif", "image/gif", "avatar"}},
+			err:   ErrFileType,
+			path:  "avatar",
+			value: "me.gif",
+		},
+		{
+			name:  "wildcard not matched",
+			files: []testFile{{"photos", "beach.jpg", "image/jpeg", "beach"}, {"photos", "notes.txt", "text/plain", "notes"}},
+			err:   ErrFileType,
+			path:  "photos",
+			value: "notes.txt",
+		},
+		{
+			name:  "map value too large",
+			files: []testFile{{"attachments[itinerary]", "plan.txt", "text/plain", "day one, day two"}},
+			err:   ErrFileTooLarge,
+			path:  "attachments[itinerary]",
+			value: "plan.txt",
+		},
+		{
+			name:  "duplicate file",
+			files: []testFile{{"resume", "a.pdf", "application/pdf", "a"}, {"resume", "b.pdf", "application/pdf", "b"}},
+			opts:  []Option{WithDuplicates(DuplicateError)},
+			err:   ErrDuplicateValue,
+			path:  "resume",
+		},
+		{
+			name:  "unknown file",
+			files: []testFile{{"banner", "banner.png", "image/png", "banner"}},
+			opts:  []Option{WithStrict()},
+			err:   ErrUnknownKey,
+			path:  "banner",
+			value: "banner.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeRequest(newMultipartRequest(t, "/upload", nil, tt.files...), &UploadForm{}, tt.opts...)
+			assert.ErrorIs(t, err, tt.err, "expected file error")
+
+			var decodeErr ErrorDecode
+			assert.True(t, errors.As(err, &decodeErr), "expected ErrorDecode")
+			assert.Equal(t, tt.path, decodeErr.Path, "expected file path")
+			assert.Equal(t, tt.value, decodeErr.Value, "expected filename")
+		})
+	}
+
+	type NotFile struct {
+		Name string `form:"name,maxsize=1MB"`
+	}
+	err := Unmarshal(nil, &NotFile{})
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+
+	type BadSize struct {
+		Avatar *multipart.FileHeader `form:"avatar,maxsize=big"`
+	}
+	err = Unmarshal(nil, &BadSize{})
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+}
+
+func TestDecoder_DecodeMultipart(t *testing.T) {
+	r := newMultipartRequest(t, "/upload", map[string]string{"title": "Holiday"}, testFile{"photos", "beach.jpg", "image/jpeg", "beach"})
+	assert.NoError(t, r.ParseMultipartForm(1<<20), "expected multipart form")
+
+	var dest UploadForm
+	err := NewDecoder().DecodeMultipart(r.MultipartForm, &dest)
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, "Holiday", dest.Title, "expected form value")
+	assert.Equal(t, "beach.jpg", dest.Photos[0].Filename, "expected file")
+
+	// File fields are left out when encoding
+	formValues, err := Marshal(dest)
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, map[string][]string{"title": {"Holiday"}}, formValues, "expected form values only")
+}
@@ -0,0 +1,119 @@
+package form
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	timePtrType = reflect.PointerTo(timeType)
+)
+
+// timeLayouts holds the layouts for each named preset of the `layout` tag option, matching the values sent by HTML
+// inputs. The first layout is used to encode, and each layout is tried in order to decode.
+var timeLayouts = map[string][]string{
+	"date":           {time.DateOnly},
+	"datetime-local": {"2006-01-02T15:04", "2006-01-02T15:04:05"},
+	"month":          {"2006-01"},
+	"time":           {"15:04", time.TimeOnly},
+}
+
+// parseTimeLayout returns the layout for the value of a `layout` tag option, or an error if the field does not hold
+// time.Time values.
+func parseTimeLayout(t reflect.Type, layout string) (string, error) {
+	if layout == "" {
+		return "", fmt.Errorf("%w: empty time layout", ErrInvalidTag)
+	}
+
+	// Layouts apply to time.Time fields, and to time.Time slice elements and map values
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t != timeType {
+		return "", fmt.Errorf("%w: time layouts are not supported for %v", ErrInvalidTag, t)
+	}
+
+	return layout, nil
+}
+
+// parseTime parses a form value with the named preset or custom layout. Values without a time zone are parsed in the
+// provided location.
+func parseTime(layout, value string, loc *time.Location) (time.Time, error) {
+	switch layout {
+	case "unix", "unixmilli":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if layout == "unix" {
+			return time.Unix(n, 0).In(loc), nil
+		}
+		return time.UnixMilli(n).In(loc), nil
+
+	case "week":
+		return parseWeek(value, loc)
+	}
+
+	layouts, ok := timeLayouts[layout]
+	if !ok {
+		layouts = []string{layout}
+	}
+
+	var err error
+	for _, l := range layouts {
+		var t time.Time
+		t, err = time.ParseInLocation(l, value, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+// formatTime formats a time with the named preset or custom layout.
+func formatTime(layout string, t time.Time) string {
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+
+	case "week":
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	}
+
+	if layouts, ok := timeLayouts[layout]; ok {
+		layout = layouts[0]
+	}
+
+	return t.Format(layout)
+}
+
+// parseWeek parses an ISO 8601 week, `2024-W34`, into midnight of the week's Monday.
+func parseWeek(value string, loc *time.Location) (time.Time, error) {
+	yearText, weekText, ok := strings.Cut(value, "-W")
+	year, yearErr := strconv.Atoi(yearText)
+	week, weekErr := strconv.Atoi(weekText)
+	if !ok || yearErr != nil || weekErr != nil || len(weekText) != 2 {
+		return time.Time{}, fmt.Errorf("parsing week %q: expected the format 2006-W01", value)
+	}
+
+	// January 4th is always in the first week of the year, and weeks start on Monday
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -(int(jan4.Weekday())+6)%7)
+	t := monday.AddDate(0, 0, (week-1)*7)
+
+	if _, w := t.ISOWeek(); week < 1 || w != week {
+		return time.Time{}, fmt.Errorf("parsing week %q: week out of range", value)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,183 @@
+package form
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type EventForm struct {
+	Day       time.Time            `form:"day,omitempty,layout=date"`
+	Starts    *time.Time           `form:"starts,omitempty,layout=datetime-local"`
+	Month     time.Time            `form:"month,omitempty,layout=month"`
+	Week      time.Time            `form:"week,omitempty,layout=week"`
+	Doors     time.Time            `form:"doors,omitempty,layout=time"`
+	Created   time.Time            `form:"created,omitempty,layout=unix"`
+	Updated   time.Time            `form:"updated,omitempty,layout=unixmilli"`
+	Custom    time.Time            `form:"custom,omitempty,layout=02/01/2006 15:04"`
+	Holidays  []time.Time          `form:"holidays,omitempty,layout=date"`
+	Deadlines map[string]time.Time `form:"deadlines,omitempty,layout=date"`
+	Published time.Time            `form:"published,omitempty"`
+	Fallback  time.Time            `form:"fallback,omitempty,layout=date,default=2024-01-01"`
+}
+
+func TestUnmarshal_TimeLayouts(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	assert.NoError(t, err, "expected time zone data")
+
+	formData := url.Values{
+		"day":            []string{"2024-08-19"},
+		"starts":         []string{"2024-08-19T05:09"},
+		"month":          []string{"2024-08"},
+		"week":           []string{"2024-W34"},
+		"doors":          []string{"05:09:30"},
+		"created":        []string{"1724044140"},
+		"updated":        []string{"1724044140123"},
+		"custom":         []string{"19/08/2024 05:09"},
+		"holidays":       []string{"2024-12-25", "2024-12-26"},
+		"deadlines[tax]": []string{"2025-01-31"},
+		"published":      []string{"2024-08-19T05:09:00Z"},
+	}
+
+	tests := []struct {
+		name string
+		opts []Option
+		loc  *time.Location
+	}{
+		{
+			name: "default location",
+			loc:  time.UTC,
+		},
+		{
+			name: "configured location",
+			opts: []Option{WithLocation(london)},
+			loc:  london,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest EventForm
+			err := Unmarshal(formData, &dest, tt.opts...)
+			assert.NoError(t, err, "expected no error")
+
+			date := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+				return time.Date(year, month, day, hour, min, sec, 0, tt.loc)
+			}
+			assert.Equal(t, EventForm{
+				Day:       date(2024, time.August, 19, 0, 0, 0),
+				Starts:    toPtr(date(2024, time.August, 19, 5, 9, 0)),
+				Month:     date(2024, time.August, 1, 0, 0, 0),
+				Week:      date(2024, time.August, 19, 0, 0, 0),
+				Doors:     date(0, time.January, 1, 5, 9, 30),
+				Created:   time.Unix(1724044140, 0).In(tt.loc),
+				Updated:   time.UnixMilli(1724044140123).In(tt.loc),
+				Custom:    date(2024, time.August, 19, 5, 9, 0),
+				Holidays:  []time.Time{date(2024, time.December, 25, 0, 0, 0), date(2024, time.December, 26, 0, 0, 0)},
+				Deadlines: map[string]time.Time{"tax": date(2025, time.January, 31, 0, 0, 0)},
+				Published: MustParseTime("2024-08-19T05:09:00Z"),
+				Fallback:  date(2024, time.January, 1, 0, 0, 0),
+			}, dest, "expected decoded times")
+		})
+	}
+}
+
+func TestUnmarshal_InvalidTimeLayouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		formData url.Values
+		err      string
+	}{
+		{
+			name:     "date",
+			formData: url.Values{"day": []string{"19/08/2024"}},
+			err:      `Unable to decode tag 'day': parsing time "19/08/2024" as "2006-01-02": cannot parse "19/08/2024" as "2006"`,
+		},
+		{
+			name:     "unix",
+			formData: url.Values{"created": []string{"yesterday"}},
+			err:      `Unable to decode tag 'created': strconv.ParseInt: parsing "yesterday": invalid syntax`,
+		},
+		{
+			name:     "week format",
+			formData: url.Values{"week": []string{"2024-34"}},
+			err:      `Unable to decode tag 'week': parsing week "2024-34": expected the format 2006-W01`,
+		},
+		{
+			name:     "week range",
+			formData: url.Values{"week": []string{"2023-W53"}},
+			err:      `Unable to decode tag 'week': parsing week "2023-W53": week out of range`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal(tt.formData, &EventForm{})
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+
+	type NotTime struct {
+		Count int `form:"count,layout=date"`
+	}
+	err := Unmarshal(url.Values{}, &NotTime{})
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+
+	type BadDefault struct {
+		Day time.Time `form:"day,layout=date,default=2024-13-01"`
+	}
+	err = Unmarshal(url.Values{}, &BadDefault{})
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+
+	// Commas separate tag options, so a layout cannot contain one
+	type CommaLayout struct {
+		Day time.Time `form:"day,layout=Jan 2, 2006"`
+	}
+	err = Unmarshal(url.Values{}, &CommaLayout{})
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+	assert.ErrorContains(t, err, "layout values cannot contain commas")
+}
+
+func TestMarshal_TimeLayouts(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	moment := time.Date(2024, time.August, 19, 5, 9, 30, 0, time.UTC)
+
+	event := EventForm{
+		Day:       moment,
+		Starts:    &moment,
+		Month:     moment,
+		Week:      moment,
+		Doors:     moment,
+		Created:   moment,
+		Updated:   moment,
+		Custom:    moment,
+		Holidays:  []time.Time{moment},
+		Deadlines: map[string]time.Time{"tax": moment},
+		Published: moment,
+	}
+
+	formValues, err := Marshal(event)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string][]string{
+		"day":            {"2024-08-19"},
+		"starts":         {"2024-08-19T05:09"},
+		"month":          {"2024-08"},
+		"week":           {"2024-W34"},
+		"doors":          {"05:09"},
+		"created":        {"1724044170"},
+		"updated":        {"1724044170000"},
+		"custom":         {"19/08/2024 05:09"},
+		"holidays":       {"2024-08-19"},
+		"deadlines[tax]": {"2024-08-19"},
+		"published":      {"2024-08-19T05:09:30Z"},
+	}, formValues, "expected formatted times")
+
+	formValues, err = Marshal(EventForm{Starts: &moment, Published: moment}, WithLocation(tokyo))
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, map[string][]string{
+		"starts":    {"2024-08-19T14:09"},
+		"published": {"2024-08-19T05:09:30Z"},
+	}, formValues, "expected times in the configured location")
+}
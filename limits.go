@@ -0,0 +1,69 @@
+package form
+
+import "sort"
+
+// limitError returns the error for form input at the form key that exceeds the named limit.
+func limitError(limit string, max int, formTag string) ErrorDecode {
+	return ErrorDecode{Path: formTag, Err: ErrorLimit{Limit: limit, Max: max, Path: formTag}}
+}
+
+// checkLen returns an error if the slice or map at the form key would hold more than `max` elements. A max of zero
+// allows any number of elements.
+func checkLen(limit string, max, n int, formTag string) error {
+	if max > 0 && n > max {
+		return limitError(limit, max, formTag)
+	}
+
+	return nil
+}
+
+// checkDepth returns an error if the form key is nested more deeply than the maximum depth.
+func (o *options) checkDepth(key string) error {
+	if o.maxDepth > 0 && o.pathSyntax.depth(key) > o.maxDepth {
+		return limitError("depth", o.maxDepth, key)
+	}
+
+	return nil
+}
+
+// checkLimits returns an error if the form exceeds the Decoder's limits on the number of keys, the size of keys and
+// values, or the nesting depth. Limits are checked before any field is decoded, and stop decoding even when the
+// Decoder aggregates errors. The first offending key in sorted order is reported, so the error does not depend on map
+// iteration order.
+func (d *decodeState) checkLimits() error {
+	o := &d.opts
+	if o.maxKeys > 0 && len(d.src)+len(d.files) > o.maxKeys {
+		return limitError("key count", o.maxKeys, "")
+	}
+	if o.maxKeySize == 0 && o.maxValueSize == 0 && o.maxDepth == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(d.src)+len(d.files))
+	for key := range d.src {
+		keys = append(keys, key)
+	}
+	for key := range d.files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if o.maxKeySize > 0 && len(key) > o.maxKeySize {
+			return limitError("key size", o.maxKeySize, key)
+		}
+
+		err := o.checkDepth(key)
+		if err != nil {
+			return err
+		}
+
+		for _, val := range d.src[key] {
+			if o.maxValueSize > 0 && len(val) > o.maxValueSize {
+				return limitError("value size", o.maxValueSize, key)
+			}
+		}
+	}
+
+	return nil
+}
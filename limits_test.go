@@ -0,0 +1,158 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type LimitedForm struct {
+	Name  string            `form:"name"`
+	Tags  []string          `form:"tags"`
+	Items []LineItem        `form:"items"`
+	Meta  map[string]string `form:"meta"`
+	Owner struct {
+		Address Address `form:"address"`
+	} `form:"owner"`
+}
+
+func TestDecoder_Limits(t *testing.T) {
+	tests := []struct {
+		name     string
+		formData url.Values
+		opts     []Option
+		limit    string
+		path     string
+	}{
+		{
+			name: "too many keys",
+			formData: url.Values{
+				"name":    []string{"Tavish"},
+				"tags":    []string{"a"},
+				"meta[a]": []string{"1"},
+			},
+			opts:  []Option{WithMaxKeys(2)},
+			limit: "key count",
+		},
+		{
+			name:     "key too large",
+			formData: url.Values{"name": []string{"Tavish"}, "meta[" + strings.Repeat("k", 32) + "]": []string{"1"}},
+			opts:     []Option{WithMaxKeySize(16)},
+			limit:    "key size",
+			path:     "meta[" + strings.Repeat("k", 32) + "]",
+		},
+		{
+			name:     "value too large",
+			formData: url.Values{"tags": []string{"a", strings.Repeat("v", 17)}},
+			opts:     []Option{WithMaxValueSize(16)},
+			limit:    "value size",
+			path:     "tags",
+		},
+		{
+			name:     "key too deep",
+			formData: url.Values{"owner[address][city]": []string{"Ullapool"}},
+			opts:     []Option{WithMaxDepth(1)},
+			limit:    "depth",
+			path:     "owner[address][city]",
+		},
+		{
+			name:     "dotted key too deep",
+			formData: url.Values{"owner.address.city": []string{"Ullapool"}},
+			opts:     []Option{WithMaxDepth(1), WithPathSyntax(PathDot)},
+			limit:    "depth",
+			path:     "owner.address.city",
+		},
+		{
+			name:     "repeated values too long",
+			formData: url.Values{"tags": []string{"a", "b", "c"}},
+			opts:     []Option{WithMaxSliceLen(2)},
+			limit:    "slice length",
+			path:     "tags",
+		},
+		{
+			name: "indexed slice too long",
+			formData: url.Values{
+				"items[0][sku]":   []string{"A1"},
+				"items[1][sku]":   []string{"B2"},
+				"items[900][sku]": []string{"C3"},
+			},
+			opts:  []Option{WithMaxSliceLen(2)},
+			limit: "slice length",
+			path:  "items",
+		},
+		{
+			name: "map too large",
+			formData: url.Values{
+				"meta[a]": []string{"1"},
+				"meta[b]": []string{"2"},
+				"meta[c]": []string{"3"},
+			},
+			opts:  []Option{WithMaxMapEntries(2)},
+			limit: "map size",
+			path:  "meta",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, decode := range map[string]func(opts ...Option) error{
+				"Decoder": func(opts ...Option) error {
+					return Unmarshal(tt.formData, &LimitedForm{}, opts...)
+				},
+				"StreamDecoder": func(opts ...Option) error {
+					return NewStreamDecoder(strings.NewReader(tt.formData.Encode()), opts...).Decode(&LimitedForm{})
+				},
+			} {
+				err := decode(append(tt.opts, WithAllErrors())...)
+				assert.ErrorIs(t, err, ErrLimitExceeded, "%s: expected ErrLimitExceeded", name)
+
+				var limitErr ErrorLimit
+				if assert.True(t, errors.As(err, &limitErr), "%s: expected ErrorLimit", name) {
+					assert.Equal(t, tt.limit, limitErr.Limit, "%s: expected limit", name)
+					// A StreamDecoder stops reading a key at the size limit, so it cannot report the key
+					if tt.limit != "key count" && (name == "Decoder" || tt.limit != "key size") {
+						assert.Equal(t, tt.path, limitErr.Path, "%s: expected path", name)
+					}
+				}
+
+				err = decode()
+				assert.NoError(t, err, "%s: expected no error without limits", name)
+			}
+		})
+	}
+}
+
+func TestDecoder_LimitsWithinBounds(t *testing.T) {
+	formData := url.Values{
+		"name":                 []string{"Tavish"},
+		"tags":                 []string{"a", "b"},
+		"items[0][sku]":        []string{"A1"},
+		"items[1][sku]":        []string{"B2"},
+		"meta[a]":              []string{"1"},
+		"meta[b]":              []string{"2"},
+		"owner[address][city]": []string{"Ullapool"},
+	}
+	opts := []Option{
+		WithMaxKeys(8),     // a StreamDecoder counts both `tags` pairs
+		WithMaxKeySize(28), // a StreamDecoder measures `owner%5Baddress%5D%5Bcity%5D`
+		WithMaxValueSize(8),
+		WithMaxDepth(2),
+		WithMaxSliceLen(2),
+		WithMaxMapEntries(2),
+	}
+
+	var expected LimitedForm
+	assert.NoError(t, Unmarshal(formData, &expected), "expected no error")
+
+	var dest LimitedForm
+	assert.NoError(t, Unmarshal(formData, &dest, opts...), "expected no error")
+	assert.Equal(t, expected, dest, "expected limits at their maximum to decode")
+
+	dest = LimitedForm{}
+	err := NewStreamDecoder(strings.NewReader(formData.Encode()), opts...).Decode(&dest)
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, expected, dest, "expected limits at their maximum to decode")
+}
@@ -0,0 +1,219 @@
+package form
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"reflect"
+	"strings"
+)
+
+var (
+	bytesType  = reflect.TypeOf([]byte(nil))
+	readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+)
+
+// partOptions holds the file name and content type of a file part from a field's `filename` and `contenttype` tag
+// options.
+type partOptions struct {
+	filename    string
+	contentType string
+}
+
+// filePart is a file to be written as a part of a multipart form.
+type filePart struct {
+	// index is the number of form values encoded before the file, to keep the order of the struct's fields.
+	index int
+
+	key         string
+	filename    string
+	contentType string
+	open        func() (io.ReadCloser, error)
+}
+
+// MultipartEncoder is responsible for encoding struct data as a `multipart/form-data` body.
+type MultipartEncoder struct {
+	writer  *multipart.Writer
+	encoder *Encoder
+}
+
+// NewMultipartEncoder creates a new MultipartEncoder instance that writes to the given writer.
+//
+// Example:
+//
+//	var body bytes.Buffer
+//	encoder := form.NewMultipartEncoder(&body)
+//	err := encoder.Encode(upload)
+//	if err != nil { ... }
+//
+//	r, err := http.NewRequest(http.MethodPost, "https://example.com/upload", &body)
+//	r.Header.Set("Content-Type", encoder.FormDataContentType())
+func NewMultipartEncoder(w io.Writer, opts ...Option) *MultipartEncoder {
+	return &MultipartEncoder{writer: multipart.NewWriter(w), encoder: NewEncoder(map[string][]string{}, opts...)}
+}
+
+// FormDataContentType returns the Content-Type of the encoded body, including the multipart boundary.
+func (m *MultipartEncoder) FormDataContentType() string {
+	return m.writer.FormDataContentType()
+}
+
+// Encode writes the provided struct as a multipart form, and closes the form. Fields of type []byte, io.Reader,
+// *multipart.FileHeader, and slices of file headers are written as file parts, and every other field as form values,
+// in the order of the struct's fields. The `filename` and `contenttype` tag options set the file name and content type
+// of a file part. File headers default to their own file name and content type, and other files to the form key and
+// `application/octet-stream`.
+//
+// Other encoders leave out io.Reader fields, and []byte fields with a `filename` or `contenttype` tag option, like
+// uploaded files, since they can only be written as file parts.
+//
+// Each MultipartEncoder encodes a single struct.
+func (m *MultipartEncoder) Encode(src any) error {
+	var (
+		pairs Pairs
+		files []filePart
+	)
+	m.encoder.pairs, m.encoder.files = &pairs, &files
+	defer func() { m.encoder.pairs, m.encoder.files = nil, nil }()
+
+	err := m.encoder.Encode(src)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i <= len(pairs); i++ {
+		for len(files) > 0 && files[0].index == i {
+			err = m.writeFile(files[0])
+			if err != nil {
+				return err
+			}
+			files = files[1:]
+		}
+
+		if i < len(pairs) {
+			err = m.writer.WriteField(pairs[i].Key, pairs[i].Value)
+			if err != nil {
+				return asEncodeError(err, pairs[i].Key)
+			}
+		}
+	}
+
+	return m.writer.Close()
+}
+
+// writeFile writes the file as a part of the form.
+func (m *MultipartEncoder) writeFile(file filePart) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition",
+		fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(file.key), quoteEscaper.Replace(file.filename)))
+	header.Set("Content-Type", file.contentType)
+
+	part, err := m.writer.CreatePart(header)
+	if err != nil {
+		return asEncodeError(err, file.key)
+	}
+
+	content, err := file.open()
+	if err != nil {
+		return asEncodeError(err, file.key)
+	}
+	defer content.Close()
+
+	_, err = io.Copy(part, content)
+	if err != nil {
+		return asEncodeError(err, file.key)
+	}
+
+	return nil
+}
+
+// quoteEscaper escapes the quoted names in a part's Content-Disposition, like mime/multipart.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// isFilePart reports whether values of the type are written as file parts of a multipart form.
+func isFilePart(t reflect.Type, r *registry) bool {
+	return t == bytesType || t == readerType || r.kindOf(t) == kindFile
+}
+
+// encodeFile records the file parts of the provided field, when encoding a multipart form. Nil files are left out.
+func (e *Encoder) encodeFile(src reflect.Value, formTag string, shouldOmitEmpty bool) error {
+	file := filePart{
+		index:       len(*e.pairs),
+		key:         formTag,
+		filename:    e.part.filename,
+		contentType: e.part.contentType,
+	}
+	if file.filename == "" {
+		file.filename = formTag
+	}
+	if file.contentType == "" {
+		file.contentType = "application/octet-stream"
+	}
+
+	switch src.Type() {
+	case bytesType:
+		if src.IsNil() || src.Len() == 0 && shouldOmitEmpty {
+			return nil
+		}
+
+		content := src.Bytes()
+		file.open = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(content)), nil
+		}
+		*e.files = append(*e.files, file)
+
+	case readerType:
+		if src.IsNil() {
+			return nil
+		}
+
+		content := src.Interface().(io.Reader)
+		file.open = func() (io.ReadCloser, error) {
+			return io.NopCloser(content), nil
+		}
+		*e.files = append(*e.files, file)
+
+	default:
+		for src.Kind() == reflect.Pointer && src.Type().Elem() != fileHeaderType {
+			if src.IsNil() {
+				return nil
+			}
+			src = src.Elem()
+		}
+
+		if src.Kind() != reflect.Slice {
+			e.encodeFileHeader(src, file)
+			return nil
+		}
+
+		for i := 0; i < src.Len(); i++ {
+			e.encodeFileHeader(src.Index(i), file)
+		}
+	}
+
+	return nil
+}
+
+// encodeFileHeader records an uploaded file, a file header or a pointer to one, as a file part.
+func (e *Encoder) encodeFileHeader(src reflect.Value, file filePart) {
+	if src.Kind() == reflect.Pointer {
+		if src.IsNil() {
+			return
+		}
+		src = src.Elem()
+	}
+
+	header := src.Interface().(multipart.FileHeader)
+	if e.part.filename == "" {
+		file.filename = header.Filename
+	}
+	if contentType := header.Header.Get("Content-Type"); e.part.contentType == "" && contentType != "" {
+		file.contentType = contentType
+	}
+	file.open = func() (io.ReadCloser, error) {
+		return header.Open()
+	}
+
+	*e.files = append(*e.files, file)
+}
@@ -0,0 +1,125 @@
+package form
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type OutboundUpload struct {
+	Title   string                  `form:"title"`
+	Notes   []byte                  `form:"notes,filename=notes.txt,contenttype=text/plain"`
+	Report  io.Reader               `form:"report,filename=report.csv"`
+	Avatar  *multipart.FileHeader   `form:"avatar"`
+	Photos  []*multipart.FileHeader `form:"photos,contenttype=image/jpeg"`
+	Skipped io.Reader               `form:"skipped"`
+	Tags    []string                `form:"tags"`
+}
+
+// readFile returns the contents of an uploaded file.
+func readFile(t *testing.T, header *multipart.FileHeader) string {
+	file, err := header.Open()
+	assert.NoError(t, err, "expected file to open")
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	assert.NoError(t, err, "expected file content")
+
+	return string(content)
+}
+
+func TestMultipartEncoder(t *testing.T) {
+	upload := newMultipartRequest(t, "/upload", nil,
+		testFile{"avatar", "me.png", "image/png", "avatar"},
+		testFile{"photos", "beach.jpg", "image/jpeg", "beach"},
+		testFile{"photos", "sunset.webp", "image/webp", "sunset"},
+	)
+	assert.NoError(t, upload.ParseMultipartForm(1<<20), "expected multipart form")
+
+	src := OutboundUpload{
+		Title:  "Holiday",
+		Notes:  []byte("day one"),
+		Report: strings.NewReader("a,b\n1,2\n"),
+		Avatar: upload.MultipartForm.File["avatar"][0],
+		Photos: upload.MultipartForm.File["photos"],
+		Tags:   []string{"beach", "sun"},
+	}
+
+	var body bytes.Buffer
+	encoder := NewMultipartEncoder(&body)
+	err := encoder.Encode(src)
+	assert.NoError(t, err, "unexpected error")
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	r.Header.Set("Content-Type", encoder.FormDataContentType())
+	assert.Contains(t, r.Header.Get("Content-Type"), "multipart/form-data; boundary=", "expected boundary Content-Type")
+
+	// Parts are written in the order of the struct's fields
+	reader, err := r.MultipartReader()
+	assert.NoError(t, err, "expected multipart body")
+
+	var parts []string
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err, "expected part")
+		parts = append(parts, part.FormName()+":"+part.FileName()+":"+part.Header.Get("Content-Type"))
+	}
+	assert.Equal(t, []string{
+		"title::",
+		"notes:notes.txt:text/plain",
+		"report:report.csv:application/octet-stream",
+		"avatar:me.png:image/png",
+		"photos:beach.jpg:image/jpeg",
+		"photos:sunset.webp:image/jpeg",
+		"tags::",
+		"tags::",
+	}, parts, "expected parts in field order")
+
+	// Outside multipart forms, readers and byte slices with file part options are left out like uploaded files
+	src.Report = strings.NewReader("a,b\n1,2\n")
+	formData, err := Marshal(src)
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, map[string][]string{"title": {"Holiday"}, "tags": {"beach", "sun"}}, formData,
+		"expected only form values")
+
+	encoded, err := EncodeToString(src)
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, "title=Holiday&tags=beach&tags=sun", encoded, "expected only form values")
+}
+
+func TestMultipartEncoder_RoundTrip(t *testing.T) {
+	src := struct {
+		Title  string `form:"title"`
+		Avatar []byte `form:"avatar,filename=me.png,contenttype=image/png"`
+	}{Title: "Holiday", Avatar: []byte("avatar")}
+
+	var body bytes.Buffer
+	encoder := NewMultipartEncoder(&body)
+	assert.NoError(t, encoder.Encode(src), "unexpected error")
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	r.Header.Set("Content-Type", encoder.FormDataContentType())
+
+	var dest UploadForm
+	err := DecodeRequest(r, &dest)
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, "Holiday", dest.Title, "expected form value")
+	assert.Equal(t, "me.png", dest.Avatar.Filename, "expected file name")
+	assert.Equal(t, "avatar", readFile(t, dest.Avatar), "expected file content")
+
+	type NotFile struct {
+		Name string `form:"name,filename=name.txt"`
+	}
+	err = NewMultipartEncoder(io.Discard).Encode(NotFile{})
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+}
@@ -0,0 +1,82 @@
+package form
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives form keys from Go field names for fields that have no tag.
+type NamingStrategy int
+
+const (
+	// NamingNone skips fields without a tag. This is the default.
+	NamingNone NamingStrategy = iota
+
+	// NamingFieldName uses the Go field name as-is: `UserID`.
+	NamingFieldName
+
+	// NamingCamelCase converts the Go field name to camelCase: `userId`.
+	NamingCamelCase
+
+	// NamingSnakeCase converts the Go field name to snake_case: `user_id`.
+	NamingSnakeCase
+
+	// NamingKebabCase converts the Go field name to kebab-case: `user-id`.
+	NamingKebabCase
+)
+
+// name returns the form key for the Go field name, or an empty string if the strategy skips untagged fields.
+func (n NamingStrategy) name(fieldName string) string {
+	switch n {
+	case NamingFieldName:
+		return fieldName
+
+	case NamingCamelCase:
+		words := splitWords(fieldName)
+		for i, word := range words {
+			word = strings.ToLower(word)
+			if i > 0 {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+			words[i] = word
+		}
+
+		return strings.Join(words, "")
+
+	case NamingSnakeCase:
+		return strings.ToLower(strings.Join(splitWords(fieldName), "_"))
+
+	case NamingKebabCase:
+		return strings.ToLower(strings.Join(splitWords(fieldName), "-"))
+
+	default:
+		return ""
+	}
+}
+
+// splitWords splits a Go identifier into words at case changes. Runs of capitals are kept together as acronyms, and
+// digits stay with the preceding word: `HTTPServerID2` is split into `HTTP`, `Server`, and `ID2`.
+func splitWords(identifier string) []string {
+	runes := []rune(identifier)
+
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+
+		// A new word starts at a capital following a lowercase letter or digit, or at the last capital of an
+		// acronym that is followed by a lowercase letter.
+		startsWord := unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) ||
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if startsWord {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
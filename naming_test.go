@@ -0,0 +1,33 @@
+package form
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamingStrategy(t *testing.T) {
+	tests := []struct {
+		fieldName string
+		camel     string
+		snake     string
+		kebab     string
+	}{
+		{fieldName: "Name", camel: "name", snake: "name", kebab: "name"},
+		{fieldName: "FirstName", camel: "firstName", snake: "first_name", kebab: "first-name"},
+		{fieldName: "UserID", camel: "userId", snake: "user_id", kebab: "user-id"},
+		{fieldName: "HTTPServer", camel: "httpServer", snake: "http_server", kebab: "http-server"},
+		{fieldName: "Address2City", camel: "address2City", snake: "address2_city", kebab: "address2-city"},
+		{fieldName: "ID", camel: "id", snake: "id", kebab: "id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fieldName, func(t *testing.T) {
+			assert.Equal(t, "", NamingNone.name(tt.fieldName), "expected untagged fields to be skipped")
+			assert.Equal(t, tt.fieldName, NamingFieldName.name(tt.fieldName), "expected field name")
+			assert.Equal(t, tt.camel, NamingCamelCase.name(tt.fieldName), "expected camelCase name")
+			assert.Equal(t, tt.snake, NamingSnakeCase.name(tt.fieldName), "expected snake_case name")
+			assert.Equal(t, tt.kebab, NamingKebabCase.name(tt.fieldName), "expected kebab-case name")
+		})
+	}
+}
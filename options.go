@@ -0,0 +1,331 @@
+package form
+
+import (
+	"strings"
+	"time"
+)
+
+// Option configures the behavior of a Decoder or Encoder.
+type Option func(*options)
+
+// options holds the configuration shared by Decoder and Encoder.
+type options struct {
+	pathSyntax   PathSyntax
+	allErrors    bool
+	strict       bool
+	allowedKeys  map[string]bool
+	tagNames     []string
+	naming       NamingStrategy
+	duplicates   DuplicatePolicy
+	validate     bool
+	loc          *time.Location
+	source       RequestSource
+	maxBodySize  int64
+	maxKeys      int
+	maxKeySize   int
+	maxValueSize int
+	maxDepth     int
+	maxSliceLen  int
+	maxMapSize   int
+	keyOrder     func(a, b string) int
+
+	// registry holds the conversions registered with Decoder.RegisterConverter and Encoder.RegisterMarshaler.
+	registry *registry
+
+	// tagKey joins tagNames, to identify the naming configuration in the struct cache.
+	tagKey string
+}
+
+// newOptions applies the provided options on top of the defaults.
+func newOptions(opts []Option) options {
+	o := options{
+		pathSyntax:  PathBracket,
+		tagNames:    []string{"form"},
+		duplicates:  DuplicateFirst,
+		maxBodySize: defaultMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.tagKey = strings.Join(o.tagNames, ",")
+
+	return o
+}
+
+// location returns the location for time values without a time zone. The default is UTC.
+func (o *options) location() *time.Location {
+	if o.loc == nil {
+		return time.UTC
+	}
+
+	return o.loc
+}
+
+// WithPathSyntax sets the syntax used to build form keys for nested struct fields. The default is PathBracket, which
+// matches the `field[key]` syntax used for maps.
+//
+// Example:
+//
+//	type Address struct {
+//		City string `form:"city"`
+//	}
+//
+//	type Checkout struct {
+//		Billing  Address `form:"billing"`  // billing[city] or billing.city
+//		Shipping Address `form:"shipping"` // shipping[city] or shipping.city
+//	}
+func WithPathSyntax(syntax PathSyntax) Option {
+	return func(o *options) {
+		o.pathSyntax = syntax
+	}
+}
+
+// WithAllErrors makes the Decoder continue past fields that fail to decode. Decode then returns a DecodeErrors value
+// holding an ErrorDecode for every failing form value, rather than stopping at the first error.
+//
+// Example:
+//
+//	err := form.Unmarshal(r.Form, &submission, form.WithAllErrors())
+//
+//	var decodeErrs form.DecodeErrors
+//	if errors.As(err, &decodeErrs) {
+//		for _, fieldErr := range decodeErrs {
+//			fmt.Println(fieldErr.Path, fieldErr.Value, fieldErr.Err)
+//		}
+//	}
+func WithAllErrors() Option {
+	return func(o *options) {
+		o.allErrors = true
+	}
+}
+
+// WithTagNames sets the struct tags that provide form keys, in order of precedence. The default is the `form` tag.
+// Fields are matched by the first tag in the list that is present, so additional names act as fallbacks.
+//
+// Example:
+//
+//	type Account struct {
+//		Name  string `schema:"name"`
+//		Email string `json:"email" schema:"emailAddress"` // emailAddress
+//		Phone string `json:"phone"`                       // phone
+//	}
+//
+//	err := form.Unmarshal(r.Form, &account, form.WithTagNames("schema", "json"))
+func WithTagNames(names ...string) Option {
+	return func(o *options) {
+		o.tagNames = names
+	}
+}
+
+// WithNaming sets the strategy that derives form keys from Go field names for fields without a tag, or with a tag that
+// has an empty name, such as `form:",omitempty"`. By default, these fields are skipped.
+//
+// Example:
+//
+//	type Account struct {
+//		UserID    int    // user_id
+//		FirstName string // first_name
+//	}
+//
+//	err := form.Unmarshal(r.Form, &account, form.WithNaming(form.NamingSnakeCase))
+func WithNaming(strategy NamingStrategy) Option {
+	return func(o *options) {
+		o.naming = strategy
+	}
+}
+
+// WithStrict makes the Decoder reject form keys that do not match a struct field, such as typos or tampered inputs.
+// Decode returns a DecodeErrors value holding an ErrorDecode that wraps ErrUnknownKey for every unknown key. The
+// provided keys are always allowed, for inputs that are not part of the struct like CSRF tokens and submit buttons.
+//
+// Example:
+//
+//	decoder := form.NewDecoder(form.WithStrict("csrf_token", "submit"))
+//
+//	err := decoder.Decode(r.Form, &submission)
+//	if errors.Is(err, form.ErrUnknownKey) { ... }
+func WithStrict(allowedKeys ...string) Option {
+	return func(o *options) {
+		o.strict = true
+		o.allowedKeys = map[string]bool{}
+		for _, key := range allowedKeys {
+			o.allowedKeys[key] = true
+		}
+	}
+}
+
+// WithDuplicates sets how the Decoder handles multiple form values for a field that holds a single value. The default
+// is DuplicateFirst. Fields can override the policy with the `dup` tag option, which applies to the field and every
+// value nested within it: `form:"amount,dup=error"`. Slice fields receive every value, so they do not accept `dup`.
+//
+// Example:
+//
+//	decoder := form.NewDecoder(form.WithDuplicates(form.DuplicateError))
+//
+//	err := decoder.Decode(r.Form, &payment)
+//	if errors.Is(err, form.ErrDuplicateValue) { ... }
+func WithDuplicates(policy DuplicatePolicy) Option {
+	return func(o *options) {
+		o.duplicates = policy
+	}
+}
+
+// WithValidation makes the Decoder check the rules in each field's `validate` tag once the form has decoded. Every
+// failing rule is returned in a DecodeErrors value, holding an ErrorDecode with an ErrorRule cause.
+//
+// The rules are:
+//   - `min=n` and `max=n` bound numbers by value, and slices and maps by their number of items
+//   - `minlen=n` and `maxlen=n` bound the number of characters in a string
+//   - `pattern=expr` matches a whole string against a regular expression. It must be the last rule in the tag.
+//   - `oneof=a|b|c` limits strings and integers to the listed values
+//   - `email` and `url` require a string to be an email address or an absolute URL
+//
+// Empty strings are only checked by the `required` tag option, so that optional inputs can be left blank. Without
+// WithValidation, `validate` tags are ignored, so structs can share them with other validation packages.
+//
+// Example:
+//
+//	type Signup struct {
+//		Email string   `form:"email" validate:"email"`
+//		Age   int      `form:"age" validate:"min=18,max=130"`
+//		Plan  string   `form:"plan" validate:"oneof=free|pro"`
+//		Tags  []string `form:"tags" validate:"max=5"`
+//	}
+//
+//	err := form.Unmarshal(r.Form, &signup, form.WithValidation())
+//	if errors.Is(err, form.ErrValidation) { ... }
+func WithValidation() Option {
+	return func(o *options) {
+		o.validate = true
+	}
+}
+
+// WithLocation sets the location for time.Time fields with a `layout` tag option. The Decoder parses times without a
+// time zone in the location, rather than in UTC, and the Encoder converts times to the location before formatting them.
+//
+// Example:
+//
+//	type Booking struct {
+//		Arrival time.Time `form:"arrival,layout=datetime-local"`
+//	}
+//
+//	london, err := time.LoadLocation("Europe/London")
+//	if err != nil { ... }
+//
+//	err = form.Unmarshal(r.Form, &booking, form.WithLocation(london))
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.loc = loc
+	}
+}
+
+// WithSource sets which parts of a request DecodeRequest reads form values from. The default is SourceMerged.
+//
+// Example:
+//
+//	err := form.DecodeRequest(r, &submission, form.WithSource(form.SourceBody))
+func WithSource(source RequestSource) Option {
+	return func(o *options) {
+		o.source = source
+	}
+}
+
+// WithMaxBodySize sets the maximum number of bytes DecodeRequest reads from a request body, including uploaded files,
+// and that a StreamDecoder reads from its input. Larger bodies fail with ErrBodyTooLarge. The default is 10 MB, and a
+// size of 0 or less removes the limit.
+//
+// Example:
+//
+//	err := form.DecodeRequest(r, &submission, form.WithMaxBodySize(1<<20))
+func WithMaxBodySize(n int64) Option {
+	return func(o *options) {
+		o.maxBodySize = n
+	}
+}
+
+// WithMaxKeys limits the number of form keys a Decoder accepts. A StreamDecoder counts each key/value pair it reads.
+// Forms with more keys fail with ErrLimitExceeded before any field is decoded. By default, the number of keys is not
+// limited.
+//
+// Example:
+//
+//	decoder := form.NewDecoder(form.WithMaxKeys(1000))
+func WithMaxKeys(n int) Option {
+	return func(o *options) {
+		o.maxKeys = n
+	}
+}
+
+// WithMaxKeySize limits the size of each form key, in bytes. A StreamDecoder measures keys before unescaping them.
+// Longer keys fail with ErrLimitExceeded. By default, keys are only limited by the maximum body size.
+//
+// Example:
+//
+//	decoder := form.NewStreamDecoder(r.Body, form.WithMaxKeySize(256))
+func WithMaxKeySize(n int) Option {
+	return func(o *options) {
+		o.maxKeySize = n
+	}
+}
+
+// WithMaxValueSize limits the size of each form value, in bytes. A StreamDecoder measures values before unescaping
+// them. Longer values fail with ErrLimitExceeded. By default, values are only limited by the maximum body size.
+//
+// Example:
+//
+//	decoder := form.NewStreamDecoder(r.Body, form.WithMaxValueSize(4<<10))
+func WithMaxValueSize(n int) Option {
+	return func(o *options) {
+		o.maxValueSize = n
+	}
+}
+
+// WithMaxDepth limits how deeply form keys are nested. Each bracketed segment, and each dotted field name when using
+// PathDot, is one level: `a[b][c]` has a depth of 2. Deeper keys fail with ErrLimitExceeded before any field is
+// decoded. By default, the depth is not limited.
+//
+// Example:
+//
+//	decoder := form.NewDecoder(form.WithMaxDepth(4))
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithMaxSliceLen limits the number of elements decoded into each slice, counting both repeated values and the indexes
+// of indexed slices. Longer slices fail with ErrLimitExceeded. By default, slices are not limited.
+//
+// Example:
+//
+//	decoder := form.NewDecoder(form.WithMaxSliceLen(100))
+func WithMaxSliceLen(n int) Option {
+	return func(o *options) {
+		o.maxSliceLen = n
+	}
+}
+
+// WithMaxMapEntries limits the number of entries decoded into each map. Larger maps fail with ErrLimitExceeded. By
+// default, maps are not limited.
+//
+// Example:
+//
+//	decoder := form.NewDecoder(form.WithMaxMapEntries(50))
+func WithMaxMapEntries(n int) Option {
+	return func(o *options) {
+		o.maxMapSize = n
+	}
+}
+
+// WithKeyOrder sets a custom order for the values from MarshalPairs, EncodeToString, EncodeQuery, and NewRequest. The
+// compare function returns a negative number when key a comes before key b, a positive number when it comes after,
+// and zero to keep the encoded order. Values for the same key always keep their order.
+//
+// Example:
+//
+//	body, err := form.EncodeToString(data, form.WithKeyOrder(strings.Compare))
+func WithKeyOrder(compare func(a, b string) int) Option {
+	return func(o *options) {
+		o.keyOrder = compare
+	}
+}
@@ -0,0 +1,86 @@
+package form
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Pair is a form key with one of its encoded values.
+type Pair struct {
+	Key   string
+	Value string
+}
+
+// Pairs is an ordered list of encoded form values. Keys with several values appear once for each value.
+type Pairs []Pair
+
+// Encode returns the pairs as an `application/x-www-form-urlencoded` string, in order: `name=Tavish&tags=a&tags=b`.
+func (p Pairs) Encode() string {
+	var b strings.Builder
+	for i, pair := range p {
+		if i > 0 {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.QueryEscape(pair.Key))
+		b.WriteByte('=')
+		b.WriteString(url.QueryEscape(pair.Value))
+	}
+
+	return b.String()
+}
+
+// Values returns the pairs as a map of form values.
+func (p Pairs) Values() map[string][]string {
+	values := make(map[string][]string, len(p))
+	for _, pair := range p {
+		values[pair.Key] = append(values[pair.Key], pair.Value)
+	}
+
+	return values
+}
+
+// keys returns the distinct keys of the pairs.
+func (p Pairs) keys() map[string]bool {
+	keys := make(map[string]bool, len(p))
+	for _, pair := range p {
+		keys[pair.Key] = true
+	}
+
+	return keys
+}
+
+// MarshalPairs serializes the provided struct into an ordered list of form values. Values follow the order of the
+// struct's fields, with map keys sorted and slice elements in order, unless WithKeyOrder sets a custom order.
+//
+// Example:
+//
+//	pairs, err := form.MarshalPairs(data)
+//	if err != nil { ... }
+//	for _, pair := range pairs {
+//		fmt.Println(pair.Key, pair.Value)
+//	}
+func MarshalPairs(src any, opts ...Option) (Pairs, error) {
+	return NewEncoder(map[string][]string{}, opts...).EncodePairs(src)
+}
+
+// EncodePairs serializes the provided struct into the destination map, like Encode, and returns the encoded values in
+// order, like MarshalPairs.
+func (e *Encoder) EncodePairs(src any) (Pairs, error) {
+	var pairs Pairs
+	e.pairs = &pairs
+	defer func() { e.pairs = nil }()
+
+	err := e.Encode(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.opts.keyOrder != nil {
+		slices.SortStableFunc(pairs, func(a, b Pair) int {
+			return e.opts.keyOrder(a.Key, b.Key)
+		})
+	}
+
+	return pairs, nil
+}
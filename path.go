@@ -0,0 +1,164 @@
+package form
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PathSyntax controls how nested struct fields are joined to the form key of their parent.
+type PathSyntax int
+
+const (
+	// PathBracket joins nested struct fields with brackets: `address[city]`.
+	PathBracket PathSyntax = iota
+
+	// PathDot joins nested struct fields with dots: `address.city`.
+	PathDot
+)
+
+// joinField returns the form key of the struct field `name` nested under `prefix`. Top-level fields have an empty
+// prefix and use their name as-is.
+func (s PathSyntax) joinField(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	if s == PathDot {
+		return prefix + "." + name
+	}
+
+	return prefix + "[" + name + "]"
+}
+
+// joinIndex returns the form key of the slice element or map entry `key` nested under `prefix`. Indexes and map keys
+// always use brackets, regardless of the path syntax.
+func joinIndex(prefix, key string) string {
+	return prefix + "[" + key + "]"
+}
+
+// cutBracket splits a form key suffix that begins with a bracketed segment into the segment and the remainder of the
+// key. For example, `[0][name]` is split into `0` and `[name]`.
+func cutBracket(s string) (segment, rest string, ok bool) {
+	if len(s) < 2 || s[0] != '[' {
+		return "", "", false
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 || strings.IndexByte(s[1:end], '[') >= 0 {
+		return "", "", false
+	}
+
+	return s[1:end], s[end+1:], true
+}
+
+// parseIndex parses a bracketed slice index. Only canonical, non-negative base-10 integers are accepted, so that each
+// index has exactly one form key.
+func parseIndex(segment string) (int, error) {
+	i, err := strconv.Atoi(segment)
+	if err != nil || segment[0] < '0' || segment[0] > '9' || (segment[0] == '0' && len(segment) > 1) {
+		return 0, fmt.Errorf("invalid slice index %q", segment)
+	}
+
+	return i, nil
+}
+
+// depth returns the number of nested segments in the form key: each bracketed segment, and each dotted field name when
+// using PathDot. Dots within brackets are part of a map key, and are not counted.
+func (s PathSyntax) depth(key string) int {
+	depth := 0
+	inBracket := false
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '[':
+			depth++
+			inBracket = true
+
+		case ']':
+			inBracket = false
+
+		case '.':
+			if s == PathDot && !inBracket {
+				depth++
+			}
+		}
+	}
+
+	return depth
+}
+
+// keyIndex indexes the nested structure of form keys, so that the keys scoped under a form key are found without
+// scanning every source key.
+type keyIndex struct {
+	// children holds the distinct bracketed segments directly under each form key. For example, the keys
+	// `items[0][name]` and `items[1][name]` have the segments `0` and `1` under `items`, and `name` under `items[0]`
+	// and `items[1]`.
+	children map[string][]string
+
+	// dotted holds the form keys that are followed by a dotted field name, when using PathDot.
+	dotted map[string]bool
+
+	// seen holds the keys whose segments are recorded in children.
+	seen map[string]bool
+
+	syntax PathSyntax
+}
+
+// newKeyIndex indexes each source key that has values in a single pass over the keys.
+func newKeyIndex(src map[string][]string, syntax PathSyntax) *keyIndex {
+	index := &keyIndex{
+		children: map[string][]string{},
+		dotted:   map[string]bool{},
+		seen:     map[string]bool{},
+		syntax:   syntax,
+	}
+
+	for key, val := range src {
+		if len(val) > 0 {
+			index.add(key)
+		}
+	}
+
+	return index
+}
+
+// add indexes the nested structure of a single key.
+func (k *keyIndex) add(key string) {
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '[':
+			segment, _, ok := cutBracket(key[i:])
+			if !ok {
+				continue
+			}
+
+			// Record each segment once, no matter how many keys are nested under it
+			child := key[:i+len(segment)+2]
+			if k.seen[child] {
+				continue
+			}
+
+			k.seen[child] = true
+			k.children[key[:i]] = append(k.children[key[:i]], segment)
+
+		case '.':
+			if k.syntax == PathDot {
+				k.dotted[key[:i]] = true
+			}
+		}
+	}
+}
+
+// segments returns the distinct bracketed segments directly under the form key.
+func (k *keyIndex) segments(formTag string) []string {
+	return k.children[formTag]
+}
+
+// hasFields reports whether any nested struct field key is scoped under the form key.
+func (k *keyIndex) hasFields(formTag string) bool {
+	if k.syntax == PathDot {
+		return k.dotted[formTag]
+	}
+
+	return len(k.children[formTag]) > 0
+}
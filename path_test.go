@@ -0,0 +1,85 @@
+package form
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyIndex(t *testing.T) {
+	src := map[string][]string{
+		"id":                    {"1"},
+		"items[0][sku]":         {"A-1"},
+		"items[0][qty]":         {"2"},
+		"items[1][sku]":         {"B-2"},
+		"meta[a][b]":            {"c"},
+		"meta[a.b]":             {"d"},
+		"billing.city":          {"Ullapool"},
+		"rows[0].name":          {"first"},
+		"empty[key]":            {},
+		"unclosed[key":          {"malformed"},
+		"nested[outer[inner]]":  {"malformed"},
+		"addresses[home][city]": {"Ullapool"},
+	}
+
+	tests := []struct {
+		name      string
+		syntax    PathSyntax
+		formTag   string
+		segments  []string
+		hasFields bool
+	}{
+		{name: "indexes", syntax: PathBracket, formTag: "items", segments: []string{"0", "1"}, hasFields: true},
+		{name: "fields under index", syntax: PathBracket, formTag: "items[0]", segments: []string{"qty", "sku"}, hasFields: true},
+		{name: "map keys", syntax: PathBracket, formTag: "meta", segments: []string{"a", "a.b"}, hasFields: true},
+		{name: "nested map keys", syntax: PathBracket, formTag: "meta[a]", segments: []string{"b"}, hasFields: true},
+		{name: "scalar key", syntax: PathBracket, formTag: "id"},
+		{name: "keys without values", syntax: PathBracket, formTag: "empty"},
+		{name: "unclosed bracket", syntax: PathBracket, formTag: "unclosed"},
+		{name: "nested brackets", syntax: PathBracket, formTag: "nested"},
+		{name: "dotted fields ignored with brackets", syntax: PathBracket, formTag: "billing"},
+		{name: "dotted fields", syntax: PathDot, formTag: "billing", hasFields: true},
+		{name: "dotted fields under index", syntax: PathDot, formTag: "rows[0]", hasFields: true},
+		{name: "bracketed fields ignored with dots", syntax: PathDot, formTag: "addresses[home]", segments: []string{"city"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := newKeyIndex(src, tt.syntax)
+
+			segments := index.segments(tt.formTag)
+			sort.Strings(segments)
+			assert.Equal(t, tt.segments, segments, "expected equal segments")
+			assert.Equal(t, tt.hasFields, index.hasFields(tt.formTag), "expected equal field presence")
+		})
+	}
+}
+
+func TestParseIndex(t *testing.T) {
+	tests := []struct {
+		segment string
+		index   int
+		err     string
+	}{
+		{segment: "0", index: 0},
+		{segment: "12", index: 12},
+		{segment: "01", err: "invalid slice index \"01\""},
+		{segment: "+1", err: "invalid slice index \"+1\""},
+		{segment: "-1", err: "invalid slice index \"-1\""},
+		{segment: "one", err: "invalid slice index \"one\""},
+		{segment: "", err: "invalid slice index \"\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.segment, func(t *testing.T) {
+			index, err := parseIndex(tt.segment)
+			if tt.err == "" {
+				assert.NoError(t, err, "unexpected error")
+				assert.Equal(t, tt.index, index, "expected equal indexes")
+			} else {
+				assert.EqualError(t, err, tt.err, "expected equal errors")
+			}
+		})
+	}
+}
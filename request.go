@@ -0,0 +1,213 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// defaultMaxBodySize is the default limit for request bodies read by DecodeRequest, matching http.Request.ParseForm.
+const defaultMaxBodySize = 10 << 20
+
+// RequestSource selects which parts of a request DecodeRequest reads form values from.
+type RequestSource int
+
+const (
+	// SourceMerged reads both the URL query and the request body. Body values are listed before query values for the
+	// same key, so they take precedence for fields that hold a single value. This is the default.
+	SourceMerged RequestSource = iota
+
+	// SourceBody reads only the request body, like http.Request.PostForm.
+	SourceBody
+
+	// SourceQuery reads only the URL query. The request body is left unread.
+	SourceQuery
+)
+
+// NewRequest builds a request with the provided struct encoded as form values. For GET, HEAD, and DELETE requests, the
+// values are added to the URL's query. For every other method, the values are sent as an
+// `application/x-www-form-urlencoded` body, and the request's Content-Type is set to match.
+//
+// Example:
+//
+//	r, err := form.NewRequest(http.MethodPost, "https://example.com/contact", contact)
+//	if err != nil { ... }
+//	resp, err := http.DefaultClient.Do(r)
+func NewRequest(method, target string, src any, opts ...Option) (*http.Request, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		err = EncodeQuery(u, src, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return http.NewRequest(method, u.String(), nil)
+	}
+
+	body, err := EncodeToString(src, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := http.NewRequest(method, u.String(), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r, nil
+}
+
+// DecodeRequest decodes the form values in the request into the provided destination struct. Values are read from the
+// URL query, and from `application/x-www-form-urlencoded` or `multipart/form-data` bodies, according to the request's
+// Content-Type. Options set the RequestSource, the maximum body size, and any other Decoder configuration.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		var submission SampleForm
+//		err := form.DecodeRequest(r, &submission, form.WithMaxBodySize(1<<20))
+//		if errors.Is(err, form.ErrBodyTooLarge) { ... }
+//	}
+func DecodeRequest(r *http.Request, dest any, opts ...Option) error {
+	return NewDecoder(opts...).DecodeRequest(r, dest)
+}
+
+// DecodeRequest decodes the form values in the request into the provided destination struct, like the package-level
+// DecodeRequest function. DecodeRequest is safe for concurrent use.
+func (d *Decoder) DecodeRequest(r *http.Request, dest any) error {
+	src, err := d.requestValues(r)
+	if err != nil {
+		return err
+	}
+
+	// Uploaded files are part of the body
+	var files map[string][]*multipart.FileHeader
+	if r.MultipartForm != nil && d.opts.source != SourceQuery {
+		files = r.MultipartForm.File
+	}
+
+	return d.decode(src, files, dest)
+}
+
+// requestValues returns the form values from the request's configured sources.
+func (d *Decoder) requestValues(r *http.Request) (map[string][]string, error) {
+	if d.opts.source == SourceQuery {
+		return parseQuery(r)
+	}
+
+	body, err := d.bodyValues(r)
+	if err != nil {
+		return nil, err
+	}
+	if d.opts.source == SourceBody {
+		return body, nil
+	}
+
+	query, err := parseQuery(r)
+	if err != nil {
+		return nil, err
+	}
+
+	src := make(map[string][]string, len(body)+len(query))
+	for key, values := range body {
+		src[key] = values
+	}
+	for key, values := range query {
+		src[key] = append(src[key], values...)
+	}
+
+	return src, nil
+}
+
+// parseQuery returns the form values in the request's URL query.
+func parseQuery(r *http.Request) (map[string][]string, error) {
+	query, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedRequest, err)
+	}
+
+	return query, nil
+}
+
+// bodyValues returns the form values in the request body. Bodies that were already parsed by
+// http.Request.ParseMultipartForm or http.Request.ParseForm are reused.
+func (d *Decoder) bodyValues(r *http.Request) (map[string][]string, error) {
+	if r.MultipartForm != nil {
+		return r.MultipartForm.Value, nil
+	}
+	if r.PostForm != nil {
+		return r.PostForm, nil
+	}
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		if r.ContentLength == 0 {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("%w: missing Content-Type", ErrUnsupportedContentType)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedRequest, err)
+	}
+
+	// Without a limit on the body, multipart forms keep as much in memory as they would by default
+	maxMemory := int64(defaultMaxBodySize)
+	if d.opts.maxBodySize > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, d.opts.maxBodySize)
+		maxMemory = d.opts.maxBodySize
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		raw, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, requestBodyError(err)
+		}
+
+		values, err := url.ParseQuery(string(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMalformedRequest, err)
+		}
+
+		return values, nil
+
+	case "multipart/form-data":
+		// The parsed form is kept on the request, so that file uploads can be read from it
+		err := r.ParseMultipartForm(maxMemory)
+		if err != nil {
+			return nil, requestBodyError(err)
+		}
+
+		return r.MultipartForm.Value, nil
+
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedContentType, mediaType)
+	}
+}
+
+// requestBodyError classifies an error from reading a request body.
+func requestBodyError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: limit is %d bytes", ErrBodyTooLarge, maxBytesErr.Limit)
+	}
+
+	return fmt.Errorf("%w: %w", ErrMalformedRequest, err)
+}
@@ -0,0 +1,269 @@
+package form
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ContactRequest struct {
+	Name    string   `form:"name"`
+	Topic   string   `form:"topic"`
+	Tags    []string `form:"tags"`
+	Message string   `form:"message"`
+}
+
+type testFile struct {
+	field       string
+	filename    string
+	contentType string
+	content     string
+}
+
+// newMultipartRequest builds a multipart/form-data request with the provided fields and files.
+func newMultipartRequest(t *testing.T, target string, fields map[string]string, files ...testFile) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		assert.NoError(t, writer.WriteField(name, value), "expected multipart field")
+	}
+	for _, file := range files {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, file.field, file.filename))
+		header.Set("Content-Type", file.contentType)
+
+		part, err := writer.CreatePart(header)
+		assert.NoError(t, err, "expected multipart file")
+		_, err = part.Write([]byte(file.content))
+		assert.NoError(t, err, "expected multipart file content")
+	}
+	assert.NoError(t, writer.Close(), "expected multipart body")
+
+	r := httptest.NewRequest(http.MethodPost, target, &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return r
+}
+
+// newURLEncodedRequest builds an application/x-www-form-urlencoded request with the provided body.
+func newURLEncodedRequest(target, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	return r
+}
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  func(t *testing.T) *http.Request
+		opts     []Option
+		expected ContactRequest
+	}{
+		{
+			name: "query",
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/contact?name=Tavish&tags=a&tags=b", nil)
+			},
+			expected: ContactRequest{Name: "Tavish", Tags: []string{"a", "b"}},
+		},
+		{
+			name: "urlencoded body",
+			request: func(t *testing.T) *http.Request {
+				return newURLEncodedRequest("/contact", "name=Tavish&message=Hello%2C+world")
+			},
+			expected: ContactRequest{Name: "Tavish", Message: "Hello, world"},
+		},
+		{
+			name: "multipart body",
+			request: func(t *testing.T) *http.Request {
+				return newMultipartRequest(t, "/contact", map[string]string{"name": "Tavish", "message": "Hello"})
+			},
+			expected: ContactRequest{Name: "Tavish", Message: "Hello"},
+		},
+		{
+			name: "merged sources prefer the body",
+			request: func(t *testing.T) *http.Request {
+				return newURLEncodedRequest("/contact?name=Query&topic=sales&tags=q", "name=Body&tags=b")
+			},
+			expected: ContactRequest{Name: "Body", Topic: "sales", Tags: []string{"b", "q"}},
+		},
+		{
+			name: "body only",
+			request: func(t *testing.T) *http.Request {
+				return newURLEncodedRequest("/contact?name=Query&topic=sales", "name=Body")
+			},
+			opts:     []Option{WithSource(SourceBody)},
+			expected: ContactRequest{Name: "Body"},
+		},
+		{
+			name: "query only",
+			request: func(t *testing.T) *http.Request {
+				return newURLEncodedRequest("/contact?name=Query&topic=sales", "name=Body")
+			},
+			opts:     []Option{WithSource(SourceQuery)},
+			expected: ContactRequest{Name: "Query", Topic: "sales"},
+		},
+		{
+			name: "parsed request",
+			request: func(t *testing.T) *http.Request {
+				r := newURLEncodedRequest("/contact?topic=sales", "name=Body")
+				assert.NoError(t, r.ParseForm(), "expected parsed form")
+				return r
+			},
+			expected: ContactRequest{Name: "Body", Topic: "sales"},
+		},
+		{
+			name: "empty body without content type",
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/contact?name=Tavish", http.NoBody)
+			},
+			expected: ContactRequest{Name: "Tavish"},
+		},
+		{
+			name: "urlencoded body without size limit",
+			request: func(t *testing.T) *http.Request {
+				return newURLEncodedRequest("/contact", "name=Body")
+			},
+			opts:     []Option{WithMaxBodySize(0)},
+			expected: ContactRequest{Name: "Body"},
+		},
+		{
+			name: "multipart body without size limit",
+			request: func(t *testing.T) *http.Request {
+				return newMultipartRequest(t, "/contact", map[string]string{"name": "Body"})
+			},
+			opts:     []Option{WithMaxBodySize(-1)},
+			expected: ContactRequest{Name: "Body"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest ContactRequest
+			err := DecodeRequest(tt.request(t), &dest, tt.opts...)
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, tt.expected, dest, "expected decoded request")
+		})
+	}
+}
+
+func TestDecodeRequest_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  func(t *testing.T) *http.Request
+		opts     []Option
+		sentinel error
+	}{
+		{
+			name: "urlencoded body too large",
+			request: func(t *testing.T) *http.Request {
+				return newURLEncodedRequest("/contact", "message="+strings.Repeat("a", 64))
+			},
+			opts:     []Option{WithMaxBodySize(32)},
+			sentinel: ErrBodyTooLarge,
+		},
+		{
+			name: "multipart body too large",
+			request: func(t *testing.T) *http.Request {
+				return newMultipartRequest(t, "/contact", map[string]string{"message": strings.Repeat("a", 1024)})
+			},
+			opts:     []Option{WithMaxBodySize(512)},
+			sentinel: ErrBodyTooLarge,
+		},
+		{
+			name: "malformed urlencoded body",
+			request: func(t *testing.T) *http.Request {
+				return newURLEncodedRequest("/contact", "name=%zz")
+			},
+			sentinel: ErrMalformedRequest,
+		},
+		{
+			name: "malformed multipart body",
+			request: func(t *testing.T) *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader("not multipart"))
+				r.Header.Set("Content-Type", "multipart/form-data; boundary=xyz")
+				return r
+			},
+			sentinel: ErrMalformedRequest,
+		},
+		{
+			name: "malformed query",
+			request: func(t *testing.T) *http.Request {
+				r := httptest.NewRequest(http.MethodGet, "/contact", nil)
+				r.URL.RawQuery = "name=%zz"
+				return r
+			},
+			sentinel: ErrMalformedRequest,
+		},
+		{
+			name: "malformed content type",
+			request: func(t *testing.T) *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader("name=Tavish"))
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded; =")
+				return r
+			},
+			sentinel: ErrMalformedRequest,
+		},
+		{
+			name: "json body",
+			request: func(t *testing.T) *http.Request {
+				r := httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader(`{"name":"Tavish"}`))
+				r.Header.Set("Content-Type", "application/json")
+				return r
+			},
+			sentinel: ErrUnsupportedContentType,
+		},
+		{
+			name: "missing content type",
+			request: func(t *testing.T) *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/contact", strings.NewReader("name=Tavish"))
+			},
+			sentinel: ErrUnsupportedContentType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeRequest(tt.request(t), &ContactRequest{}, tt.opts...)
+			assert.ErrorIs(t, err, tt.sentinel, "expected sentinel error")
+		})
+	}
+
+	// Bodies are not read when decoding only the query
+	r := httptest.NewRequest(http.MethodPost, "/contact?name=Tavish", strings.NewReader(`{}`))
+	r.Header.Set("Content-Type", "application/json")
+	var dest ContactRequest
+	err := DecodeRequest(r, &dest, WithSource(SourceQuery))
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, "Tavish", dest.Name, "expected query values")
+}
+
+func TestNewRequest(t *testing.T) {
+	contact := ContactRequest{Name: "Tavish", Topic: "sales", Tags: []string{"new"}}
+
+	r, err := NewRequest(http.MethodPost, "https://example.com/contact?ref=home", contact)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"), "expected urlencoded body")
+	assert.Equal(t, "ref=home", r.URL.RawQuery, "expected query to be kept")
+
+	body, err := io.ReadAll(r.Body)
+	assert.NoError(t, err, "unexpected error")
+	assert.Equal(t, "name=Tavish&topic=sales&tags=new&message=", string(body), "expected encoded body")
+
+	r, err = NewRequest(http.MethodGet, "https://example.com/contact?ref=home", contact)
+	assert.NoError(t, err, "unexpected error")
+	assert.Empty(t, r.Header.Get("Content-Type"), "expected no body")
+	assert.Equal(t, "ref=home&name=Tavish&topic=sales&tags=new&message=", r.URL.RawQuery, "expected encoded query")
+
+	_, err = NewRequest(http.MethodPost, "https://example.com/contact", 42)
+	assert.ErrorIs(t, err, ErrInvalidSource, "expected ErrInvalidSource")
+}
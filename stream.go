@@ -0,0 +1,783 @@
+package form
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StreamDecoder decodes `application/x-www-form-urlencoded` input from a reader, one key/value pair at a time. Each pair
+// is decoded into the destination struct as soon as it is read, so the values are never collected into a map. Only
+// the keys that need revisiting once the input has been read are kept: the indexes of indexed slices, the fields with
+// the `required` or `default` tag options, and scalar keys when the DuplicatePolicy must detect repeated values.
+type StreamDecoder struct {
+	r       io.Reader
+	decoder *Decoder
+}
+
+// NewStreamDecoder creates a new StreamDecoder instance that reads from the given reader. The input is limited by
+// WithMaxBodySize, and single keys and values by WithMaxKeySize and WithMaxValueSize.
+//
+// Example:
+//
+//	decoder := form.NewStreamDecoder(r.Body, form.WithMaxBodySize(64<<20), form.WithMaxValueSize(4<<10))
+//
+//	var bulk ImportForm
+//	err := decoder.Decode(&bulk)
+//	if errors.Is(err, form.ErrBodyTooLarge) { ... }
+func NewStreamDecoder(r io.Reader, opts ...Option) *StreamDecoder {
+	return &StreamDecoder{r: r, decoder: NewDecoder(opts...)}
+}
+
+// Decode reads the input to the end, decoding each key/value pair into the provided destination struct. The `dest`
+// must be a pointer to a struct. Values are decoded like Decoder.Decode.
+func (s *StreamDecoder) Decode(dest any) error {
+	// Ensure dest has a value that is a non-nil pointer to a struct
+	val := reflect.ValueOf(dest)
+	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: destination (%v) must be a pointer to a struct", ErrInvalidDestination, reflect.TypeOf(dest))
+	}
+
+	// Get value of dest pointer
+	val = val.Elem()
+
+	opts := &s.decoder.opts
+	reader := &pairReader{r: bufio.NewReader(s.r), opts: opts}
+	state := &streamState{
+		decodeState: &decodeState{Decoder: s.decoder, duplicates: opts.duplicates},
+		seen:        map[string]uint64{},
+		counts:      map[string]int{},
+		joined:      map[string]string{},
+		present:     map[string]bool{},
+		slices:      map[string]*streamSlice{},
+		entries:     map[string][]string{},
+		failed:      map[string]bool{},
+		structs:     map[reflect.Type]*streamStruct{},
+	}
+
+	for pairs := 1; ; pairs++ {
+		key, value, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// Limits on the form stop decoding, even when the Decoder aggregates errors
+		if opts.maxKeys > 0 && pairs > opts.maxKeys {
+			return limitError("key count", opts.maxKeys, key)
+		}
+		err = opts.checkDepth(key)
+		if err != nil {
+			return err
+		}
+
+		state.key = key
+		err = state.decodePair(val, kindStruct, "", key, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := state.finishStruct(val, "")
+	if err != nil {
+		return err
+	}
+
+	// Values are only validated once every field has decoded
+	if len(state.errs) == 0 {
+		state.validateValue(val, "")
+	}
+
+	if len(state.errs) > 0 {
+		return state.errs
+	}
+
+	return nil
+}
+
+// pairReader tokenizes `application/x-www-form-urlencoded` input into key/value pairs.
+type pairReader struct {
+	r    *bufio.Reader
+	opts *options
+
+	// read is the number of bytes read so far.
+	read int64
+}
+
+// next returns the next key/value pair, unescaped, or io.EOF at the end of the input. Empty pairs are skipped.
+func (p *pairReader) next() (key, value string, err error) {
+	for {
+		rawKey, sep, err := p.readToken("=&", p.opts.maxKeySize)
+		if errors.Is(err, errTokenTooLarge) {
+			return "", "", limitError("key size", p.opts.maxKeySize, "")
+		}
+		if err != nil {
+			return "", "", err
+		}
+
+		var rawValue string
+		if sep == '=' {
+			rawValue, sep, err = p.readToken("&", p.opts.maxValueSize)
+			if errors.Is(err, errTokenTooLarge) {
+				key, _ := url.QueryUnescape(rawKey)
+				return "", "", limitError("value size", p.opts.maxValueSize, key)
+			}
+			if err != nil {
+				return "", "", err
+			}
+		}
+
+		if rawKey == "" && rawValue == "" {
+			if sep == 0 {
+				return "", "", io.EOF
+			}
+
+			continue
+		}
+
+		if strings.Contains(rawKey, ";") || strings.Contains(rawValue, ";") {
+			return "", "", fmt.Errorf("%w: invalid semicolon separator in query", ErrMalformedRequest)
+		}
+
+		key, err = url.QueryUnescape(rawKey)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: %w", ErrMalformedRequest, err)
+		}
+
+		value, err = url.QueryUnescape(rawValue)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: %w", ErrMalformedRequest, err)
+		}
+
+		return key, value, nil
+	}
+}
+
+// errTokenTooLarge is returned by pairReader.readToken for keys and values longer than their limit.
+var errTokenTooLarge = errors.New("token too large")
+
+// readToken reads the input up to the next separator, returning the token and the separator, or zero at the end of the
+// input. Tokens longer than the limit return errTokenTooLarge, and input beyond the maximum body size returns
+// ErrBodyTooLarge. A limit of zero allows tokens of any length.
+func (p *pairReader) readToken(separators string, limit int) (string, byte, error) {
+	// Tokens are scanned in the reader's buffer, and only copied across buffer refills
+	var token []byte
+	for {
+		_, err := p.r.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return string(token), 0, nil
+		}
+		if err != nil {
+			return "", 0, requestBodyError(err)
+		}
+
+		buf, _ := p.r.Peek(p.r.Buffered())
+		end := bytes.IndexAny(buf, separators)
+		n, read := end, end+1
+		if end < 0 {
+			n, read = len(buf), len(buf)
+		}
+
+		p.read += int64(read)
+		if p.opts.maxBodySize > 0 && p.read > p.opts.maxBodySize {
+			return "", 0, fmt.Errorf("%w: limit is %d bytes", ErrBodyTooLarge, p.opts.maxBodySize)
+		}
+		if limit > 0 && len(token)+n > limit {
+			return "", 0, errTokenTooLarge
+		}
+
+		if end < 0 {
+			token = append(token, buf...)
+			_, _ = p.r.Discard(read)
+			continue
+		}
+
+		sep := buf[end]
+		var value string
+		if token == nil {
+			value = string(buf[:end])
+		} else {
+			value = string(append(token, buf[:end]...))
+		}
+		_, _ = p.r.Discard(read)
+
+		return value, sep, nil
+	}
+}
+
+// streamState holds the state of a single call to StreamDecoder.Decode.
+type streamState struct {
+	*decodeState
+
+	// key is the form key of the pair being decoded. The form keys of the values it is nested within are its prefixes.
+	key string
+
+	// seen holds the scalar form keys that received values, for the DuplicatePolicies that must detect repeated
+	// values. The fields of a struct are held as bits, by position, under the struct's form key, so there is one entry
+	// per struct rather than per field. Other form keys, such as map values, are held as a single bit. Keys are not
+	// tracked with DuplicateLast.
+	seen map[string]uint64
+
+	// counts holds the number of repeated values received by each scalar form key.
+	counts map[string]int
+
+	// joined holds the values received by each scalar form key so far, for fields with DuplicateJoin.
+	joined map[string]string
+
+	// present holds the form key of each tracked struct field that received values. The value is true once the field
+	// has received a non-empty value.
+	present map[string]bool
+
+	// slices holds the elements of each indexed slice, by the slice's form key.
+	slices map[string]*streamSlice
+
+	// entries holds the map keys under the form key of each map whose values are revisited, in the order they first
+	// appeared.
+	entries map[string][]string
+
+	// failed holds the form keys of the map entries whose values failed to decode. Like Decoder.Decode, these entries
+	// are left out of the map, even if later values under them decode.
+	failed map[string]bool
+
+	// structs holds the fields of each struct type, and which of them are revisited once the input has been read.
+	structs map[reflect.Type]*streamStruct
+}
+
+// streamSlice holds the elements of an indexed slice, in the order their indexes first appeared.
+type streamSlice struct {
+	// kind is the kind of the slice's elements.
+	kind formKind
+
+	// positions holds the position of each index's element in the slice. It is only built once the indexes stop
+	// counting up from 0, since the position of each element is its index until then.
+	positions map[int]int
+
+	// indexes holds the index of each element in the slice.
+	indexes []int
+}
+
+// position returns the position of the element with the index, if the index has appeared before.
+func (e *streamSlice) position(index int) (int, bool) {
+	if e.positions == nil {
+		return index, index < len(e.indexes)
+	}
+
+	i, ok := e.positions[index]
+	return i, ok
+}
+
+// add records the index of a new element, appended to the slice, and returns its position.
+func (e *streamSlice) add(index int) int {
+	i := len(e.indexes)
+	if e.positions == nil && index != i {
+		e.positions = make(map[int]int, i+1)
+		for position, index := range e.indexes {
+			e.positions[index] = position
+		}
+	}
+	if e.positions != nil {
+		e.positions[index] = i
+	}
+	e.indexes = append(e.indexes, index)
+
+	return i
+}
+
+// streamStruct holds the fields of a struct type, and which of them are revisited once the input has been read:
+// required fields, fields with default values or invalid tags, and fields holding values with tracked fields or
+// indexed slices.
+type streamStruct struct {
+	info    *structInfo
+	tracked []bool
+
+	// any reports whether any field is tracked.
+	any bool
+}
+
+// tag returns the form key of the value whose key continues with `rest`, which is a prefix of the current key.
+func (s *streamState) tag(rest string) string {
+	return s.key[:len(s.key)-len(rest)]
+}
+
+// fields returns the fields of the struct type. They are looked up once per call to Decode, rather than once per pair.
+func (s *streamState) fields(t reflect.Type) *streamStruct {
+	if fields, ok := s.structs[t]; ok {
+		return fields
+	}
+
+	// Recursive types are assumed to be tracked while their fields are inspected
+	info := cachedStructInfo(t, &s.opts)
+	fields := &streamStruct{info: info, tracked: make([]bool, len(info.fields)), any: true}
+	s.structs[t] = fields
+
+	any := false
+	for i, field := range info.fields {
+		switch {
+		case field.required || field.defaults != nil || field.err != nil:
+			fields.tracked[i] = true
+		case field.kind == kindStruct || field.kind == kindIndexedSlice || field.kind == kindMap:
+			fields.tracked[i] = s.finishes(t.Field(field.index).Type)
+		}
+		any = any || fields.tracked[i]
+	}
+	fields.any = any
+
+	return fields
+}
+
+// finishes reports whether values of the type are revisited once the input has been read: structs with tracked
+// fields, indexed slices, whose elements are sorted, and maps and slices of such values.
+func (s *streamState) finishes(t reflect.Type) bool {
+	kind := s.opts.registry.kindOf(t)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch kind {
+	case kindStruct:
+		return s.fields(t).any
+
+	case kindIndexedSlice:
+		return true
+
+	case kindMap:
+		return s.finishes(t.Elem())
+
+	default:
+		return false
+	}
+}
+
+// decodePair decodes a single value into the destination value, of the given kind, with the form key `formTag`. The
+// remainder of the value's key, below `formTag`, is in `rest`.
+func (s *streamState) decodePair(dest reflect.Value, kind formKind, formTag, rest, value string) error {
+	switch kind {
+	case kindScalar:
+		if rest != "" {
+			return s.unknownKey(formTag+rest, value)
+		}
+
+		return s.decodeStreamValue(dest, formTag, value, "", -1)
+
+	case kindFile:
+		return s.unknownKey(formTag+rest, value)
+	}
+
+	// Decode the element the pointer references.
+	for dest.Kind() == reflect.Pointer {
+		ensurePointerIsSet(dest)
+		dest = dest.Elem()
+	}
+
+	switch kind {
+	case kindSlice:
+		if rest != "" {
+			return s.unknownKey(formTag+rest, value)
+		}
+
+		err := checkLen("slice length", s.opts.maxSliceLen, dest.Len()+1, formTag)
+		if err != nil {
+			return s.collect(err, formTag, "")
+		}
+
+		elem := reflect.New(dest.Type().Elem()).Elem()
+		err = s.decodeValue(elem, value, formTag)
+		if err != nil {
+			return s.collect(err, formTag, strconv.Itoa(dest.Len()))
+		}
+		dest.Set(reflect.Append(dest, elem))
+
+		return nil
+
+	case kindIndexedSlice:
+		return s.decodeIndexedPair(dest, formTag, rest, value)
+
+	case kindMap:
+		return s.decodeMapPair(dest, formTag, rest, value)
+
+	default:
+		return s.decodeFieldPair(dest, formTag, rest, value)
+	}
+}
+
+// decodeStreamValue decodes a value into a scalar field. If the form key receives more than one value, the value is
+// chosen by the field's DuplicatePolicy. Struct fields are identified by the struct's form key and the field's
+// position; other values have no position.
+func (s *streamState) decodeStreamValue(dest reflect.Value, formTag, value, structTag string, position int) error {
+	switch s.duplicates {
+	case DuplicateLast:
+		// Each value replaces the previous one, so there is no need to detect repeated values
+		return s.decodeValue(dest, value, formTag)
+
+	case DuplicateJoin:
+		if joined, ok := s.joined[formTag]; ok {
+			value = joined + "," + value
+		}
+		s.joined[formTag] = value
+
+		return s.decodeValue(dest, value, formTag)
+	}
+
+	if !s.repeated(formTag, structTag, position) {
+		return s.decodeValue(dest, value, formTag)
+	}
+
+	// Repeated values are only reported once per form key
+	s.counts[formTag]++
+	if s.duplicates == DuplicateError && s.counts[formTag] == 1 {
+		return ErrorDecode{Path: formTag, Err: fmt.Errorf("%w: received more than one value", ErrDuplicateValue)}
+	}
+
+	return nil
+}
+
+// repeated records that the scalar form key received a value, and reports whether it had received one before.
+func (s *streamState) repeated(formTag, structTag string, position int) bool {
+	if position < 0 || position >= 64 {
+		structTag, position = formTag, 0
+	}
+
+	seen := s.seen[structTag]
+	s.seen[structTag] = seen | 1<<position
+
+	return seen&(1<<position) != 0
+}
+
+// decodeIndexedPair decodes a value into the slice element with the index in the next segment of the key.
+func (s *streamState) decodeIndexedPair(dest reflect.Value, formTag, rest, value string) error {
+	segment, remainder, ok := cutBracket(rest)
+	if !ok {
+		return s.unknownKey(formTag+rest, value)
+	}
+
+	index, err := parseIndex(segment)
+	if err != nil {
+		return s.collect(err, formTag, segment)
+	}
+
+	elements, ok := s.slices[formTag]
+	if !ok {
+		elements = &streamSlice{kind: s.opts.registry.kindOf(dest.Type().Elem())}
+		s.slices[formTag] = elements
+	}
+
+	i, ok := elements.position(index)
+	if !ok {
+		err = checkLen("slice length", s.opts.maxSliceLen, dest.Len()+1, formTag)
+		if err != nil {
+			return s.collect(err, formTag, "")
+		}
+
+		i = elements.add(index)
+		dest.Set(reflect.Append(dest, reflect.New(dest.Type().Elem()).Elem()))
+	}
+
+	elemTag := s.tag(remainder)
+	return s.collect(s.decodePair(dest.Index(i), elements.kind, elemTag, remainder, value), elemTag, segment)
+}
+
+// decodeMapPair decodes a value into the map entry with the key in the next segment of the form key. Map values are
+// copied, updated, and stored again, since they cannot be modified in place.
+func (s *streamState) decodeMapPair(dest reflect.Value, formTag, rest, value string) error {
+	segment, remainder, ok := cutBracket(rest)
+	if !ok {
+		return s.unknownKey(formTag+rest, value)
+	}
+
+	entryTag := s.tag(remainder)
+	mapType := dest.Type()
+	mapKey := reflect.New(mapType.Key()).Elem()
+	err := s.decodeValue(mapKey, segment, entryTag)
+	if err != nil {
+		return s.collect(err, entryTag, segment)
+	}
+
+	// Entries that failed to decode are still decoded, to report every error, but are left out of the map
+	failed := s.failed[entryTag]
+	mapVal := reflect.New(mapType.Elem()).Elem()
+	if existing := dest.MapIndex(mapKey); existing.IsValid() {
+		mapVal.Set(existing)
+	} else if !failed {
+		err = checkLen("map size", s.opts.maxMapSize, dest.Len()+1, formTag)
+		if err != nil {
+			return s.collect(err, formTag, "")
+		}
+
+		if s.finishes(mapType.Elem()) {
+			s.entries[formTag] = append(s.entries[formTag], segment)
+		}
+	}
+
+	// Leave out map values that failed to decode
+	errCount := len(s.errs)
+	kind := s.opts.registry.kindOf(mapType.Elem())
+	err = s.collect(s.decodePair(mapVal, kind, entryTag, remainder, value), entryTag, segment)
+	if err != nil || len(s.errs) > errCount {
+		s.failed[entryTag] = true
+		if !dest.IsNil() {
+			dest.SetMapIndex(mapKey, reflect.Value{})
+			if dest.Len() == 0 {
+				dest.Set(reflect.Zero(mapType))
+			}
+		}
+
+		return err
+	}
+	if failed {
+		return nil
+	}
+
+	if dest.IsNil() {
+		dest.Set(reflect.MakeMap(mapType))
+	}
+	dest.SetMapIndex(mapKey, mapVal)
+
+	return nil
+}
+
+// decodeFieldPair decodes a value into the struct field named in the next segment of the key.
+func (s *streamState) decodeFieldPair(dest reflect.Value, formTag, rest, value string) error {
+	name, remainder, ok := s.cutField(formTag, rest)
+	if !ok {
+		return s.unknownKey(formTag+rest, value)
+	}
+
+	destType := dest.Type()
+	fields := s.fields(destType)
+	i, ok := fields.info.byTag[name]
+	if !ok {
+		return s.unknownKey(formTag+rest, value)
+	}
+
+	field := fields.info.fields[i]
+	fieldTag := s.tag(remainder)
+	if field.err != nil {
+		return s.collect(ErrorDecode{Path: fieldTag, Field: field.name, Struct: destType, Err: field.err}, fieldTag, "")
+	}
+
+	// Scalars and slices are only filled by non-empty values, like the HTML `required` attribute
+	if fields.tracked[i] {
+		filled := value != "" || (field.kind != kindScalar && field.kind != kindSlice)
+		s.present[fieldTag] = s.present[fieldTag] || filled
+	}
+
+	// Fields with a `dup` tag option override the policy for every value nested within them
+	duplicates, layout := s.duplicates, s.layout
+	if field.duplicates != 0 {
+		s.duplicates = field.duplicates
+	}
+	s.layout = field.layout
+
+	// Errors are attributed to the innermost struct field that produced them
+	errCount := len(s.errs)
+	var err error
+	if field.kind == kindScalar && remainder == "" {
+		err = s.decodeStreamValue(dest.Field(field.index), fieldTag, value, formTag, i)
+	} else {
+		err = s.decodePair(dest.Field(field.index), field.kind, fieldTag, remainder, value)
+	}
+	err = s.collect(err, fieldTag, "")
+	s.duplicates, s.layout = duplicates, layout
+
+	return s.attribute(err, errCount, destType, field.name)
+}
+
+// cutField splits the next struct field name from the remainder of a form key. Top-level fields are not preceded by a
+// separator.
+func (s *streamState) cutField(formTag, rest string) (name, remainder string, ok bool) {
+	if formTag != "" {
+		if s.opts.pathSyntax != PathDot {
+			return cutBracket(rest)
+		}
+
+		var found bool
+		rest, found = strings.CutPrefix(rest, ".")
+		if !found {
+			return "", "", false
+		}
+	}
+
+	separators := "["
+	if s.opts.pathSyntax == PathDot {
+		separators = "[."
+	}
+
+	end := strings.IndexAny(rest, separators)
+	if end < 0 {
+		end = len(rest)
+	}
+
+	return rest[:end], rest[end:], end > 0
+}
+
+// unknownKey records an error for a form key that does not match any field, when the Decoder is created with
+// WithStrict. Like Decoder.Decode, unknown keys do not stop decoding.
+func (s *streamState) unknownKey(key, value string) error {
+	if !s.opts.strict || s.opts.allowedKeys[key] {
+		return nil
+	}
+
+	s.errs = append(s.errs, ErrorDecode{Path: key, Value: value, Err: ErrUnknownKey})
+
+	return nil
+}
+
+// attribute sets the struct field of the errors recorded since errCount, and of the returned error, if they do not
+// have one.
+func (s *streamState) attribute(err error, errCount int, structType reflect.Type, name string) error {
+	for j := errCount; j < len(s.errs); j++ {
+		s.errs[j] = s.errs[j].withField(structType, name)
+	}
+	if err != nil {
+		return err.(ErrorDecode).withField(structType, name)
+	}
+
+	return nil
+}
+
+// finishStruct applies the `required` and `default` tag options to the struct fields that received no values, and
+// to the structs nested in fields that did.
+func (s *streamState) finishStruct(dest reflect.Value, prefix string) error {
+	destType := dest.Type()
+	fields := s.fields(destType)
+	for i, field := range fields.info.fields {
+		if !fields.tracked[i] {
+			continue
+		}
+
+		fieldVal := dest.Field(field.index)
+		fieldTag := s.opts.pathSyntax.joinField(prefix, field.tag)
+
+		filled, present := s.present[fieldTag]
+		if field.required && !filled {
+			err := s.collect(ErrorDecode{Path: fieldTag, Field: field.name, Struct: destType, Err: ErrMissing}, fieldTag, "")
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		errCount := len(s.errs)
+		var err error
+		if present {
+			err = s.finishValue(fieldVal, fieldTag)
+		} else {
+			// Without source values, decoding only applies default values and reports invalid tags
+			s.layout = field.layout
+			err = s.collect(field.decode(s.decodeState, fieldVal, fieldTag), fieldTag, "")
+			s.layout = ""
+		}
+
+		err = s.attribute(err, errCount, destType, field.name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// finishValue applies the `required` and `default` tag options to the structs within the value.
+func (s *streamState) finishValue(dest reflect.Value, formTag string) error {
+	kind := s.opts.registry.kindOf(dest.Type())
+	for dest.Kind() == reflect.Pointer {
+		if dest.IsNil() {
+			return nil
+		}
+		dest = dest.Elem()
+	}
+
+	switch kind {
+	case kindStruct:
+		return s.finishStruct(dest, formTag)
+
+	case kindIndexedSlice:
+		elements := s.slices[formTag]
+		if elements == nil {
+			return nil
+		}
+
+		s.sortElements(dest, elements)
+		if !s.finishes(dest.Type().Elem()) {
+			return nil
+		}
+
+		for i, index := range elements.indexes {
+			segment := strconv.Itoa(index)
+			elemTag := joinIndex(formTag, segment)
+			err := s.collect(s.finishValue(dest.Index(i), elemTag), elemTag, segment)
+			if err != nil {
+				return err
+			}
+		}
+
+	case kindMap:
+		mapType := dest.Type()
+		for _, segment := range s.entries[formTag] {
+			entryTag := joinIndex(formTag, segment)
+			mapKey := reflect.New(mapType.Key()).Elem()
+			if s.decodeValue(mapKey, segment, entryTag) != nil {
+				continue
+			}
+
+			existing := dest.MapIndex(mapKey)
+			if !existing.IsValid() {
+				continue
+			}
+
+			// Leave out map values that failed to finish, like values that failed to decode
+			errCount := len(s.errs)
+			mapVal := reflect.New(mapType.Elem()).Elem()
+			mapVal.Set(existing)
+			err := s.collect(s.finishValue(mapVal, entryTag), entryTag, segment)
+			if err != nil {
+				return err
+			}
+			if len(s.errs) > errCount {
+				dest.SetMapIndex(mapKey, reflect.Value{})
+				continue
+			}
+			dest.SetMapIndex(mapKey, mapVal)
+		}
+
+		if dest.Len() == 0 {
+			dest.Set(reflect.Zero(mapType))
+		}
+	}
+
+	return nil
+}
+
+// sortElements sorts the elements of an indexed slice by index, as Decoder.Decode orders them. Elements are appended in
+// the order their indexes first appear in the input, so they are sorted in place once the input has been read.
+func (s *streamState) sortElements(dest reflect.Value, elements *streamSlice) {
+	if sort.IntsAreSorted(elements.indexes) {
+		return
+	}
+
+	sort.Stable(indexedElements{indexes: elements.indexes, swap: reflect.Swapper(dest.Interface())})
+}
+
+// indexedElements sorts the elements of an indexed slice by their indexes.
+type indexedElements struct {
+	indexes []int
+	swap    func(i, j int)
+}
+
+func (e indexedElements) Len() int           { return len(e.indexes) }
+func (e indexedElements) Less(i, j int) bool { return e.indexes[i] < e.indexes[j] }
+
+func (e indexedElements) Swap(i, j int) {
+	e.indexes[i], e.indexes[j] = e.indexes[j], e.indexes[i]
+	e.swap(i, j)
+}
@@ -0,0 +1,353 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamDecoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		formData url.Values
+		dest     func() any
+		opts     []Option
+	}{
+		{
+			name: "nested structs",
+			formData: url.Values{
+				"email":          []string{"tavish@example.com"},
+				"billing[city]":  []string{"Ullapool"},
+				"shipping[city]": []string{"Inverness"},
+			},
+			dest: func() any { return &CheckoutForm{} },
+		},
+		{
+			name: "dotted nested structs",
+			formData: url.Values{
+				"billing.street": []string{"1 Shore St"},
+				"shipping.city":  []string{"Inverness"},
+			},
+			dest: func() any { return &CheckoutForm{} },
+			opts: []Option{WithPathSyntax(PathDot)},
+		},
+		{
+			name: "without body size limit",
+			formData: url.Values{
+				"email":         []string{"tavish@example.com"},
+				"billing[city]": []string{"Ullapool"},
+			},
+			dest: func() any { return &CheckoutForm{} },
+			opts: []Option{WithMaxBodySize(0)},
+		},
+		{
+			name: "indexed slices",
+			formData: url.Values{
+				"items[0][sku]":      []string{"A1"},
+				"items[0][qty]":      []string{"2"},
+				"items[1][sku]":      []string{"B2"},
+				"items[10][sku]":     []string{"D4"},
+				"items[2][sku]":      []string{"C3"},
+				"itemPtrs[0][qty]":   []string{"1"},
+				"addresses[0][city]": []string{"Ullapool"},
+			},
+			dest: func() any { return &OrderForm{} },
+		},
+		{
+			name: "nested maps",
+			formData: url.Values{
+				"addresses[home][city]":     []string{"Ullapool"},
+				"addresses[home][street]":   []string{"1 Shore St"},
+				"matrix[a][b]":              []string{"1"},
+				"items[x][0][sku]":          []string{"A1"},
+				"deep[a][b][0][city]":       []string{"Inverness"},
+				"meta[first]":               []string{"tavish"},
+				"meta[last]":                []string{"degroot"},
+				"addresses[work][city]":     []string{"Dundee"},
+				"items[x][1][qty]":          []string{"3"},
+				"deep[a][b][1][street]":     []string{"2 High St"},
+				"addresses[holiday][city]":  []string{"Skye"},
+				"addresses[holiday][other]": []string{"ignored"},
+			},
+			dest: func() any { return &NestedMapForm{} },
+		},
+		{
+			name: "typed map keys",
+			formData: url.Values{
+				"intKeys[1]":           []string{"one"},
+				"uintKeys[2]":          []string{"1", "2"},
+				"boolKeys[true]":       []string{"yes"},
+				"textKeys[10.0.0.1]":   []string{"router"},
+				"textKeys[10.0.0.255]": []string{"broadcast"},
+			},
+			dest: func() any { return &TypedKeyForm{} },
+		},
+		{
+			name: "scalars, slices, and defaults",
+			formData: url.Values{
+				"day":            []string{"2024-08-19"},
+				"holidays":       []string{"2024-12-25", "2024-12-26"},
+				"deadlines[tax]": []string{"2025-01-31"},
+				"week":           []string{"2024-W34"},
+			},
+			dest: func() any { return &EventForm{} },
+		},
+		{
+			name: "registered converters",
+			formData: url.Values{
+				"total":        []string{"12.34"},
+				"lines":        []string{"1.00", "2.50"},
+				"budgets[ops]": []string{"100.00"},
+			},
+			dest: func() any { return &InvoiceForm{} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewDecoder(tt.opts...)
+			decoder.RegisterConverter(reflect.TypeOf(Money{}), parseMoney)
+			expected := tt.dest()
+			assert.NoError(t, decoder.Decode(tt.formData, expected), "expected no error")
+
+			stream := NewStreamDecoder(strings.NewReader(tt.formData.Encode()), tt.opts...)
+			stream.decoder.RegisterConverter(reflect.TypeOf(Money{}), parseMoney)
+			dest := tt.dest()
+			err := stream.Decode(dest)
+			assert.NoError(t, err, "expected no error")
+			assert.Equal(t, expected, dest, "expected the same values as Decoder")
+		})
+	}
+}
+
+func TestStreamDecoder_Order(t *testing.T) {
+	type Search struct {
+		Query  string     `form:"q"`
+		Last   string     `form:"last,dup=last"`
+		Joined string     `form:"joined,dup=join"`
+		Items  []LineItem `form:"items"`
+	}
+
+	input := "q=first&q=second&last=a&last=b&joined=a&joined=b&items[5][sku]=B&items[2][sku]=A&items[5][qty]=1&&"
+
+	var dest Search
+	err := NewStreamDecoder(strings.NewReader(input)).Decode(&dest)
+	assert.NoError(t, err, "expected no error")
+	assert.Equal(t, Search{
+		Query:  "first",
+		Last:   "b",
+		Joined: "a,b",
+		Items:  []LineItem{{SKU: "A"}, {SKU: "B", Qty: 1}},
+	}, dest, "expected duplicate policies and elements in index order")
+
+	err = NewStreamDecoder(strings.NewReader("q=a&q=b&q=c"), WithDuplicates(DuplicateError)).Decode(&dest)
+	assert.ErrorIs(t, err, ErrDuplicateValue, "expected ErrDuplicateValue")
+}
+
+func TestStreamDecoder_Errors(t *testing.T) {
+	type Contact struct {
+		Name  string `form:"name,required"`
+		Phone string `form:"phone"`
+		Age   int    `form:"age"`
+	}
+	type Directory struct {
+		Owner    Contact   `form:"owner"`
+		Contacts []Contact `form:"contacts"`
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		err   error
+		paths []string
+	}{
+		{
+			name:  "missing required fields",
+			input: "owner[phone]=555&contacts[0][name]=Jane&contacts[1][name]=",
+			opts:  []Option{WithAllErrors()},
+			err:   ErrMissing,
+			paths: []string{"owner[name]", "contacts[1][name]"},
+		},
+		{
+			name:  "invalid values",
+			input: "owner[name]=Tavish&owner[age]=old&contacts[0][name]=Jane&contacts[0][age]=young",
+			opts:  []Option{WithAllErrors()},
+			err:   strconv.ErrSyntax,
+			paths: []string{"owner[age]", "contacts[0][age]"},
+		},
+		{
+			name:  "unknown keys",
+			input: "owner[name]=Tavish&owner[email]=t@example.com&nickname=tav&owner[name][first]=Tavish",
+			opts:  []Option{WithStrict()},
+			err:   ErrUnknownKey,
+			paths: []string{"owner[email]", "nickname", "owner[name][first]"},
+		},
+		{
+			name:  "key too large",
+			input: "owner[name]=Tavish&" + strings.Repeat("k", 65) + "=x",
+			opts:  []Option{WithMaxKeySize(64)},
+			err:   ErrLimitExceeded,
+			paths: []string{""},
+		},
+		{
+			name:  "value too large",
+			input: "owner[name]=" + strings.Repeat("v", 65),
+			opts:  []Option{WithMaxValueSize(64)},
+			err:   ErrLimitExceeded,
+			paths: []string{"owner[name]"},
+		},
+		{
+			name:  "body too large",
+			input: "owner[name]=Tavish&owner[phone]=555",
+			opts:  []Option{WithMaxBodySize(20)},
+			err:   ErrBodyTooLarge,
+		},
+		{
+			name:  "malformed escape",
+			input: "owner[name]=%zz",
+			err:   ErrMalformedRequest,
+		},
+		{
+			name:  "semicolon separator",
+			input: "owner[name]=Tavish;owner[phone]=555",
+			err:   ErrMalformedRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewStreamDecoder(strings.NewReader(tt.input), tt.opts...).Decode(&Directory{})
+			assert.ErrorIs(t, err, tt.err, "expected error")
+
+			var paths []string
+			var decodeErrs DecodeErrors
+			var decodeErr ErrorDecode
+			switch {
+			case errors.As(err, &decodeErrs):
+				for _, decodeErr := range decodeErrs {
+					paths = append(paths, decodeErr.Path)
+				}
+			case errors.As(err, &decodeErr):
+				paths = append(paths, decodeErr.Path)
+			}
+			assert.Equal(t, tt.paths, paths, "expected error paths")
+		})
+	}
+
+	err := NewStreamDecoder(strings.NewReader("")).Decode(Directory{})
+	assert.ErrorIs(t, err, ErrInvalidDestination, "expected ErrInvalidDestination")
+
+	type BadTag struct {
+		Page int `form:"page,dup=sometimes"`
+	}
+	err = NewStreamDecoder(strings.NewReader("page=1")).Decode(&BadTag{})
+	assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+}
+
+func TestStreamDecoder_MapErrors(t *testing.T) {
+	type Contact struct {
+		Name string `form:"name,required"`
+		Age  int    `form:"age"`
+	}
+	type Directory struct {
+		Teams map[string]Contact `form:"teams"`
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected Directory
+	}{
+		{
+			name:     "missing required field",
+			formData: url.Values{"teams[a][age]": []string{"30"}, "teams[b][name]": []string{"Jane"}},
+			expected: Directory{Teams: map[string]Contact{"b": {Name: "Jane"}}},
+		},
+		{
+			name:     "every entry fails",
+			formData: url.Values{"teams[a][age]": []string{"30"}},
+			expected: Directory{},
+		},
+		{
+			name:     "invalid value before valid values",
+			formData: url.Values{"teams[a][age]": []string{"old"}, "teams[a][name]": []string{"Jane"}},
+			expected: Directory{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected Directory
+			expectedErr := Unmarshal(tt.formData, &expected, WithAllErrors())
+			assert.Error(t, expectedErr, "expected error")
+			assert.Equal(t, tt.expected, expected, "expected failed entries to be left out")
+
+			// Values.Encode sorts the keys, so invalid values are read before the valid values of their entry
+			var dest Directory
+			err := NewStreamDecoder(strings.NewReader(tt.formData.Encode()), WithAllErrors()).Decode(&dest)
+			assert.Equal(t, expectedErr, err, "expected the errors of Decoder.Decode")
+			assert.Equal(t, tt.expected, dest, "expected failed entries to be left out")
+		})
+	}
+}
+
+type ImportRow struct {
+	SKU string `form:"sku"`
+	Qty int    `form:"qty"`
+}
+
+type ImportForm struct {
+	Rows []ImportRow `form:"rows"`
+}
+
+// importBody returns an urlencoded bulk-import body with 10,000 rows.
+func importBody() string {
+	var input strings.Builder
+	for i := 0; i < 10000; i++ {
+		fmt.Fprintf(&input, "rows[%d][sku]=SKU-%d&rows[%d][qty]=%d&", i, i, i, i)
+	}
+
+	return input.String()
+}
+
+func BenchmarkStreamDecodeManyKeys(b *testing.B) {
+	body := importBody()
+	decoder := NewDecoder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var dest ImportForm
+		err := (&StreamDecoder{r: strings.NewReader(body), decoder: decoder}).Decode(&dest)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseQueryDecodeManyKeys(b *testing.B) {
+	body := importBody()
+	decoder := NewDecoder()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		formData, err := url.ParseQuery(body)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		var dest ImportForm
+		err = decoder.Decode(formData, &dest)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
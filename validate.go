@@ -0,0 +1,383 @@
+package form
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// FormValidator is implemented by types that check their own values once a form has decoded, such as checks that
+// compare several fields. Decode calls Validate on the destination struct and on every value nested within it that
+// implements FormValidator, and reports a returned error as an ErrorDecode with the value's form key.
+type FormValidator interface {
+	Validate() error
+}
+
+var (
+	formValidatorType = reflect.TypeOf((*FormValidator)(nil)).Elem()
+
+	// validateCache holds whether values of each type, with a field naming configuration, need to be validated. Types
+	// analyzed with registered conversions are cached in their registry instead.
+	validateCache sync.Map
+)
+
+// rule is a validation rule parsed from a field's `validate` tag.
+type rule struct {
+	name  string
+	param string
+
+	// valid reports whether the field's value satisfies the rule. Pointers are dereferenced before the value is
+	// checked, and nil pointers are not checked.
+	valid func(val reflect.Value) bool
+}
+
+// parseRules parses the rules in a `validate` tag for a field of the given type. Rules are separated by commas, except
+// for `pattern`, which takes the rest of the tag so that its expression can contain commas.
+func parseRules(t reflect.Type, tag string, o *options) ([]rule, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var rules []rule
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "pattern=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, param, _ := strings.Cut(part, "=")
+		valid, err := newRule(t, name, param, o)
+		if err != nil {
+			return nil, fmt.Errorf("%w: validation rule %q: %w", ErrInvalidTag, part, err)
+		}
+
+		rules = append(rules, rule{name: name, param: param, valid: valid})
+	}
+
+	return rules, nil
+}
+
+// newRule returns the check for the named rule and its parameter, or an error if the rule does not apply to the type.
+func newRule(t reflect.Type, name, param string, o *options) (func(val reflect.Value) bool, error) {
+	switch name {
+	case "min", "max":
+		if isNumber(t) {
+			return newBoundRule(t, name == "min", param, o)
+		}
+
+		// Slices and maps are bounded by their number of items
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				return nil, err
+			}
+
+			if name == "min" {
+				return func(val reflect.Value) bool { return val.Len() >= n }, nil
+			}
+			return func(val reflect.Value) bool { return val.Len() <= n }, nil
+		}
+
+	case "minlen", "maxlen":
+		if t.Kind() == reflect.String {
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				return nil, err
+			}
+
+			if name == "minlen" {
+				return stringRule(func(s string) bool { return utf8.RuneCountInString(s) >= n }), nil
+			}
+			return stringRule(func(s string) bool { return utf8.RuneCountInString(s) <= n }), nil
+		}
+
+	case "pattern":
+		if t.Kind() == reflect.String {
+			_, err := regexp.Compile(param)
+			if err != nil {
+				return nil, err
+			}
+
+			// The expression must match the whole string, not just a part of it
+			re := regexp.MustCompile(`^(?:` + param + `)$`)
+			return stringRule(re.MatchString), nil
+		}
+
+	case "oneof":
+		allowed := map[string]bool{}
+		for _, option := range strings.Split(param, "|") {
+			allowed[option] = true
+		}
+
+		switch t.Kind() {
+		case reflect.String:
+			return stringRule(func(s string) bool { return allowed[s] }), nil
+
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return func(val reflect.Value) bool { return allowed[strconv.FormatInt(val.Int(), 10)] }, nil
+
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return func(val reflect.Value) bool { return allowed[strconv.FormatUint(val.Uint(), 10)] }, nil
+		}
+
+	case "email":
+		if t.Kind() == reflect.String {
+			return stringRule(isEmail), nil
+		}
+
+	case "url":
+		if t.Kind() == reflect.String {
+			return stringRule(isURL), nil
+		}
+
+	default:
+		return nil, errors.New("unknown rule")
+	}
+
+	return nil, fmt.Errorf("not supported for %v", t)
+}
+
+// newBoundRule returns the check for a `min` or `max` rule on a number. The bound is decoded like a form value, so
+// durations can be bounded with `min=1s`.
+func newBoundRule(t reflect.Type, isMin bool, param string, o *options) (func(val reflect.Value) bool, error) {
+	bound := reflect.New(t).Elem()
+	err := newProbeState(o).decodeValue(bound, param, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return func(val reflect.Value) bool {
+		var cmp int
+		switch val.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			cmp = compare(val.Int(), bound.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			cmp = compare(val.Uint(), bound.Uint())
+		default:
+			cmp = compare(val.Float(), bound.Float())
+		}
+
+		if isMin {
+			return cmp >= 0
+		}
+		return cmp <= 0
+	}, nil
+}
+
+// stringRule returns a check that applies fn to non-empty strings. Empty strings are left to the `required` tag
+// option, so optional fields can be left blank.
+func stringRule(fn func(s string) bool) func(val reflect.Value) bool {
+	return func(val reflect.Value) bool {
+		s := val.String()
+		return s == "" || fn(s)
+	}
+}
+
+// isNumber reports whether the type is an integer or floating point number. Types that decode from text, such as
+// those implementing encoding.TextUnmarshaler, are excluded.
+func isNumber(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// compare returns -1, 0, or 1 as a is less than, equal to, or greater than b.
+func compare[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isEmail reports whether the string is a bare email address, without a display name: `name@example.com`.
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// isURL reports whether the string is an absolute URL with a scheme and host: `https://example.com/path`.
+func isURL(s string) bool {
+	u, err := url.ParseRequestURI(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// validateStruct checks the validation rules of the struct's fields, and validates the values nested within them. Every
+// failure is collected, whether or not the Decoder is created with WithAllErrors.
+func (d *decodeState) validateStruct(val reflect.Value, prefix string) {
+	valType := val.Type()
+	for _, field := range cachedStructInfo(valType, &d.opts).fields {
+		fieldVal := val.Field(field.index)
+		fieldTag := d.opts.pathSyntax.joinField(prefix, field.tag)
+
+		elem := fieldVal
+		for elem.Kind() == reflect.Pointer && !elem.IsNil() {
+			elem = elem.Elem()
+		}
+
+		if d.opts.validate && elem.Kind() != reflect.Pointer {
+			for _, r := range field.rules {
+				if !r.valid(elem) {
+					d.errs = append(d.errs, ErrorDecode{
+						Path:   fieldTag,
+						Field:  field.name,
+						Struct: valType,
+						Err:    ErrorRule{Rule: r.name, Param: r.param},
+					})
+				}
+			}
+		}
+
+		d.validateValue(fieldVal, fieldTag)
+	}
+}
+
+// validateValue validates the structs, slice elements, and map values nested within the value, then calls the value's
+// Validate method if it implements FormValidator. Nested values are validated first, so a struct's Validate method
+// runs after its fields have been checked.
+func (d *decodeState) validateValue(val reflect.Value, formTag string) {
+	if !needsValidation(val.Type(), &d.opts) {
+		return
+	}
+
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return
+		}
+
+		val = val.Elem()
+	}
+
+	switch d.opts.registry.kindOf(val.Type()) {
+	case kindStruct:
+		d.validateStruct(val, formTag)
+
+	case kindSlice, kindIndexedSlice:
+		for i := 0; i < val.Len(); i++ {
+			d.validateValue(val.Index(i), joinIndex(formTag, strconv.Itoa(i)))
+		}
+
+	case kindMap:
+		// Map entries are checked in key order, so that errors are reported in a stable order. Values are copied so
+		// that pointer receiver Validate methods can be called.
+		keys := make([]string, 0, val.Len())
+		entries := make(map[string]reflect.Value, val.Len())
+		for _, key := range val.MapKeys() {
+			formKey := formatMapKey(key)
+			keys = append(keys, formKey)
+			entries[formKey] = reflect.New(val.Type().Elem()).Elem()
+			entries[formKey].Set(val.MapIndex(key))
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			d.validateValue(entries[key], joinIndex(formTag, key))
+		}
+	}
+
+	d.callValidator(val, formTag)
+}
+
+// callValidator calls the value's Validate method, if the value or a pointer to it implements FormValidator. A failure
+// is attributed to the value's form key, which is empty for the destination struct.
+func (d *decodeState) callValidator(val reflect.Value, formTag string) {
+	valType := val.Type()
+	if val.CanAddr() {
+		val = val.Addr()
+	}
+
+	validator, ok := val.Interface().(FormValidator)
+	if !ok {
+		return
+	}
+
+	err := validator.Validate()
+	if err != nil {
+		d.errs = append(d.errs, ErrorDecode{Path: formTag, Struct: valType, Err: err})
+	}
+}
+
+// needsValidation reports whether values of the type have validation rules or FormValidator implementations to check,
+// either directly or in the values nested within them. Types without either are skipped when validating.
+func needsValidation(t reflect.Type, o *options) bool {
+	cache := &validateCache
+	if o.registry != nil {
+		cache = &o.registry.validations
+	}
+
+	key := structKey{typ: t, tagNames: o.tagKey, naming: o.naming, validate: o.validate}
+	if needs, ok := cache.Load(key); ok {
+		return needs.(bool)
+	}
+
+	needs := hasValidation(t, o, map[reflect.Type]bool{})
+	cache.Store(key, needs)
+
+	return needs
+}
+
+// hasValidation reports whether the type, or any type nested within it, has validation rules or implements
+// FormValidator. Types that are already being visited are skipped, so that recursive types terminate.
+func hasValidation(t reflect.Type, o *options, visiting map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if reflect.PointerTo(t).Implements(formValidatorType) {
+		return true
+	}
+
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+
+	switch o.registry.kindOf(t) {
+	case kindStruct:
+		for _, field := range cachedStructInfo(t, o).fields {
+			if len(field.rules) > 0 || hasValidation(t.Field(field.index).Type, o, visiting) {
+				return true
+			}
+		}
+
+	case kindSlice, kindIndexedSlice, kindMap:
+		return hasValidation(t.Elem(), o, visiting)
+	}
+
+	return false
+}
+
+// formatMapKey formats a map key as it appears in form keys.
+func formatMapKey(key reflect.Value) string {
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	return fmt.Sprint(key.Interface())
+}
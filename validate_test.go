@@ -0,0 +1,320 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Signup struct {
+	Email    string                   `form:"email" validate:"email"`
+	Website  string                   `form:"website" validate:"url"`
+	Username string                   `form:"username" validate:"minlen=3,maxlen=8,pattern=^[a-z]{1,}$"`
+	Code     string                   `form:"code" validate:"pattern=[0-9]+"`
+	Plan     string                   `form:"plan" validate:"oneof=free|pro"`
+	Seats    uint                     `form:"seats" validate:"oneof=1|5|10"`
+	Age      *int                     `form:"age" validate:"min=18,max=130"`
+	Score    float64                  `form:"score" validate:"min=0.5"`
+	Timeout  time.Duration            `form:"timeout" validate:"max=1m"`
+	Tags     []string                 `form:"tags" validate:"max=2"`
+	Meta     map[string]string        `form:"meta" validate:"min=1"`
+	Contacts []SignupContact          `form:"contacts"`
+	Home     *SignupContact           `form:"home"`
+	Groups   map[string]SignupContact `form:"groups"`
+}
+
+type SignupContact struct {
+	Name string `form:"name" validate:"maxlen=4"`
+}
+
+func TestUnmarshal_Validation(t *testing.T) {
+	valid := url.Values{
+		"email":    []string{"tavish@example.com"},
+		"website":  []string{"https://example.com/about"},
+		"username": []string{"tavish"},
+		"code":     []string{"123"},
+		"plan":     []string{"pro"},
+		"seats":    []string{"5"},
+		"age":      []string{"18"},
+		"score":    []string{"0.5"},
+		"timeout":  []string{"1m"},
+		"tags":     []string{"a", "b"},
+		"meta[a]":  []string{"1"},
+	}
+
+	tests := []struct {
+		name     string
+		formData url.Values
+		expected []ErrorDecode
+	}{
+		{
+			name:     "valid values",
+			formData: valid,
+		},
+		{
+			name: "empty strings are not checked",
+			formData: url.Values{
+				"email":    []string{""},
+				"website":  []string{""},
+				"username": []string{""},
+				"plan":     []string{""},
+				"seats":    []string{"1"},
+				"score":    []string{"1"},
+				"meta[a]":  []string{"1"},
+			},
+		},
+		{
+			name: "invalid values",
+			formData: url.Values{
+				"email":    []string{"Tavish <tavish@example.com>"},
+				"website":  []string{"/about"},
+				"username": []string{"TavishDeGroot"},
+				"code":     []string{"abc1"},
+				"plan":     []string{"enterprise"},
+				"seats":    []string{"2"},
+				"age":      []string{"17"},
+				"score":    []string{"0.25"},
+				"timeout":  []string{"2m"},
+				"tags":     []string{"a", "b", "c"},
+			},
+			expected: []ErrorDecode{
+				{Path: "email", Field: "Email", Err: ErrorRule{Rule: "email"}},
+				{Path: "website", Field: "Website", Err: ErrorRule{Rule: "url"}},
+				{Path: "username", Field: "Username", Err: ErrorRule{Rule: "maxlen", Param: "8"}},
+				{Path: "username", Field: "Username", Err: ErrorRule{Rule: "pattern", Param: "^[a-z]{1,}$"}},
+				{Path: "code", Field: "Code", Err: ErrorRule{Rule: "pattern", Param: "[0-9]+"}},
+				{Path: "plan", Field: "Plan", Err: ErrorRule{Rule: "oneof", Param: "free|pro"}},
+				{Path: "seats", Field: "Seats", Err: ErrorRule{Rule: "oneof", Param: "1|5|10"}},
+				{Path: "age", Field: "Age", Err: ErrorRule{Rule: "min", Param: "18"}},
+				{Path: "score", Field: "Score", Err: ErrorRule{Rule: "min", Param: "0.5"}},
+				{Path: "timeout", Field: "Timeout", Err: ErrorRule{Rule: "max", Param: "1m"}},
+				{Path: "tags", Field: "Tags", Err: ErrorRule{Rule: "max", Param: "2"}},
+				{Path: "meta", Field: "Meta", Err: ErrorRule{Rule: "min", Param: "1"}},
+			},
+		},
+		{
+			name: "nested values",
+			formData: url.Values{
+				"seats":             []string{"1"},
+				"score":             []string{"1"},
+				"meta[a]":           []string{"1"},
+				"contacts[0][name]": []string{"Jane"},
+				"contacts[1][name]": []string{"Tavish"},
+				"home[name]":        []string{"DeGroot"},
+				"groups[b][name]":   []string{"Jane"},
+				"groups[a][name]":   []string{"Soldier"},
+				"groups[c][name]":   []string{"Medic"},
+			},
+			expected: []ErrorDecode{
+				{Path: "contacts[1][name]", Field: "Name", Err: ErrorRule{Rule: "maxlen", Param: "4"}},
+				{Path: "home[name]", Field: "Name", Err: ErrorRule{Rule: "maxlen", Param: "4"}},
+				{Path: "groups[a][name]", Field: "Name", Err: ErrorRule{Rule: "maxlen", Param: "4"}},
+				{Path: "groups[c][name]", Field: "Name", Err: ErrorRule{Rule: "maxlen", Param: "4"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest Signup
+			err := Unmarshal(tt.formData, &dest, WithValidation())
+			if tt.expected == nil {
+				assert.NoError(t, err, "expected no error")
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrValidation, "expected ErrValidation")
+
+			var decodeErrs DecodeErrors
+			assert.True(t, errors.As(err, &decodeErrs), "expected DecodeErrors")
+			for i := range decodeErrs {
+				decodeErrs[i].Struct = nil
+			}
+			assert.Equal(t, tt.expected, []ErrorDecode(decodeErrs), "expected an error for each failed rule")
+		})
+	}
+
+	// Rules are not checked unless validation is enabled
+	err := Unmarshal(url.Values{"plan": []string{"enterprise"}}, &Signup{})
+	assert.NoError(t, err, "expected no error without validation")
+
+	// Rules are not checked when a value fails to decode
+	err = Unmarshal(url.Values{"plan": []string{"enterprise"}, "age": []string{"x"}}, &Signup{}, WithValidation())
+	assert.NotErrorIs(t, err, ErrValidation, "expected decode errors only")
+
+	formData := url.Values{"plan": []string{"enterprise"}, "age": []string{"17"}, "seats": []string{"1"}, "score": []string{"1"}}
+	err = Unmarshal(formData, &Signup{}, WithValidation())
+	assert.EqualError(t, err, "Unable to decode tag 'plan': failed validation rule 'oneof=free|pro'; "+
+		"Unable to decode tag 'age': failed validation rule 'min=18'; "+
+		"Unable to decode tag 'meta': failed validation rule 'min=1'")
+}
+
+func TestUnmarshal_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		dest any
+		err  *regexp.Regexp
+	}{
+		{
+			name: "unknown rule",
+			dest: &struct {
+				Name string `form:"name" validate:"shouty"`
+			}{},
+			err: regexp.MustCompile(`validation rule "shouty": unknown rule`),
+		},
+		{
+			name: "unsupported type",
+			dest: &struct {
+				Name string `form:"name" validate:"min=3"`
+			}{},
+			err: regexp.MustCompile(`validation rule "min=3": not supported for string`),
+		},
+		{
+			name: "invalid bound",
+			dest: &struct {
+				Age int `form:"age" validate:"min=eighteen"`
+			}{},
+			err: regexp.MustCompile(`validation rule "min=eighteen": .*invalid syntax`),
+		},
+		{
+			name: "invalid pattern",
+			dest: &struct {
+				Name string `form:"name" validate:"pattern=[a-z"`
+			}{},
+			err: regexp.MustCompile(`validation rule "pattern=\[a-z": error parsing regexp`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal(url.Values{}, tt.dest, WithValidation())
+			assert.ErrorIs(t, err, ErrInvalidTag, "expected ErrInvalidTag")
+			assert.Regexp(t, tt.err, err.Error(), "expected rule error message")
+
+			err = Unmarshal(url.Values{}, tt.dest)
+			assert.NoError(t, err, "expected rules to be ignored without WithValidation")
+		})
+	}
+}
+
+type Booking struct {
+	Start  time.Time             `form:"start"`
+	End    time.Time             `form:"end"`
+	Guests []BookingGuest        `form:"guests"`
+	Rooms  map[string]RoomNumber `form:"rooms"`
+	Lead   *BookingGuest         `form:"lead"`
+}
+
+func (b Booking) Validate() error {
+	if !b.End.After(b.Start) {
+		return errors.New("end must be after start")
+	}
+
+	return nil
+}
+
+type BookingGuest struct {
+	Name string `form:"name" validate:"maxlen=6"`
+	Age  int    `form:"age"`
+}
+
+func (g *BookingGuest) Validate() error {
+	if g.Age < 18 && g.Name == "" {
+		return errors.New("minors must be named")
+	}
+
+	return nil
+}
+
+type RoomNumber string
+
+func (r RoomNumber) Validate() error {
+	if len(r) != 3 {
+		return fmt.Errorf("invalid room number %q", string(r))
+	}
+
+	return nil
+}
+
+func TestUnmarshal_FormValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		formData url.Values
+		opts     []Option
+		expected []ErrorDecode
+	}{
+		{
+			name: "valid values",
+			formData: url.Values{
+				"start":           []string{"2024-08-19T05:09:00Z"},
+				"end":             []string{"2024-08-20T05:09:00Z"},
+				"guests[0][name]": []string{"Tavish"},
+				"guests[0][age]":  []string{"12"},
+				"rooms[a]":        []string{"101"},
+			},
+		},
+		{
+			name: "invalid values",
+			formData: url.Values{
+				"start":          []string{"2024-08-19T05:09:00Z"},
+				"end":            []string{"2024-08-19T05:09:00Z"},
+				"guests[0][age]": []string{"30"},
+				"guests[1][age]": []string{"12"},
+				"rooms[b]":       []string{"1"},
+				"rooms[a]":       []string{"101"},
+				"rooms[c]":       []string{"1002"},
+				"lead[age]":      []string{"5"},
+			},
+			expected: []ErrorDecode{
+				{Path: "guests[1]", Struct: reflect.TypeOf(BookingGuest{}), Err: errors.New("minors must be named")},
+				{Path: "rooms[b]", Struct: reflect.TypeOf(RoomNumber("")), Err: errors.New(`invalid room number "1"`)},
+				{Path: "rooms[c]", Struct: reflect.TypeOf(RoomNumber("")), Err: errors.New(`invalid room number "1002"`)},
+				{Path: "lead", Struct: reflect.TypeOf(BookingGuest{}), Err: errors.New("minors must be named")},
+				{Path: "", Struct: reflect.TypeOf(Booking{}), Err: errors.New("end must be after start")},
+			},
+		},
+		{
+			name: "with validation rules",
+			formData: url.Values{
+				"start":           []string{"2024-08-19T05:09:00Z"},
+				"end":             []string{"2024-08-20T05:09:00Z"},
+				"guests[0][name]": []string{"Jane Doe"},
+				"guests[0][age]":  []string{"12"},
+			},
+			opts: []Option{WithValidation()},
+			expected: []ErrorDecode{
+				{Path: "guests[0][name]", Field: "Name", Struct: reflect.TypeOf(BookingGuest{}), Err: ErrorRule{Rule: "maxlen", Param: "6"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest Booking
+			err := Unmarshal(tt.formData, &dest, tt.opts...)
+			if tt.expected == nil {
+				assert.NoError(t, err, "expected no error")
+				return
+			}
+
+			var decodeErrs DecodeErrors
+			assert.True(t, errors.As(err, &decodeErrs), "expected DecodeErrors")
+			assert.Equal(t, tt.expected, []ErrorDecode(decodeErrs), "expected an error for each failed validator")
+		})
+	}
+
+	err := Unmarshal(url.Values{"start": []string{"2024-08-19T05:09:00Z"}}, &Booking{})
+	assert.EqualError(t, err, "Unable to decode form: end must be after start")
+
+	// Validators are not called when a value fails to decode
+	err = Unmarshal(url.Values{"start": []string{"soon"}}, &Booking{})
+	var decodeErr ErrorDecode
+	assert.True(t, errors.As(err, &decodeErr), "expected ErrorDecode")
+	assert.Equal(t, "start", decodeErr.Path, "expected decode error only")
+}